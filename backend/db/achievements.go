@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// UnlockAchievements records the given achievements for a player and
+// returns only the ones that were not already unlocked.
+func (r *Repository) UnlockAchievements(ctx context.Context, userID string, achievementIDs []string) ([]string, error) {
+	var unlocked []string
+	for _, id := range achievementIDs {
+		res, err := r.db.ExecContext(ctx,
+			`INSERT INTO player_achievements (user_id, achievement_id, unlocked_at)
+			 VALUES ($1, $2, NOW())
+			 ON CONFLICT (user_id, achievement_id) DO NOTHING`,
+			userID, id,
+		)
+		if err != nil {
+			return unlocked, err
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			unlocked = append(unlocked, id)
+		}
+	}
+	return unlocked, nil
+}
+
+// ListAchievements returns when each of the player's achievements was
+// unlocked, keyed by achievement ID.
+func (r *Repository) ListAchievements(ctx context.Context, userID string) (map[string]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT achievement_id, unlocked_at FROM player_achievements WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	unlocked := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var at time.Time
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, err
+		}
+		unlocked[id] = at
+	}
+	return unlocked, rows.Err()
+}
@@ -0,0 +1,211 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Arena player statuses.
+const (
+	ArenaWaiting = "waiting"
+	ArenaPlaying = "playing"
+	ArenaIdle    = "idle"
+)
+
+// Arena is a time-boxed event in which players are paired continuously.
+type Arena struct {
+	ArenaID  string
+	Mode     string
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// IsRunning reports whether the arena is accepting games at t.
+func (a *Arena) IsRunning(t time.Time) bool {
+	return !t.Before(a.StartsAt) && t.Before(a.EndsAt)
+}
+
+// ArenaPlayer is one participant's standing in an arena.
+type ArenaPlayer struct {
+	UserID       string
+	Username     string
+	Rating       int
+	Status       string
+	MatchID      string
+	Points       int
+	Streak       int
+	GamesPlayed  int
+	WaitingSince time.Time
+}
+
+// CreateArena inserts a new arena.
+func (r *Repository) CreateArena(ctx context.Context, a *Arena) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO arenas (arena_id, mode, starts_at, ends_at) VALUES ($1, $2, $3, $4)`,
+		a.ArenaID, a.Mode, a.StartsAt, a.EndsAt,
+	)
+	return err
+}
+
+// GetArena loads an arena. Returns sql.ErrNoRows if it does not exist.
+func (r *Repository) GetArena(ctx context.Context, arenaID string) (*Arena, error) {
+	var a Arena
+	err := r.db.QueryRowContext(ctx,
+		`SELECT arena_id, mode, starts_at, ends_at FROM arenas WHERE arena_id = $1`,
+		arenaID,
+	).Scan(&a.ArenaID, &a.Mode, &a.StartsAt, &a.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// JoinArena adds a player to the arena's pairing pool, or puts a returning
+// idle player back into it. Players already in a game are left untouched.
+func (r *Repository) JoinArena(ctx context.Context, arenaID, userID, username string, rating int) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO arena_players (arena_id, user_id, username, rating, status, waiting_since)
+		 VALUES ($1, $2, $3, $4, 'waiting', NOW())
+		 ON CONFLICT (arena_id, user_id) DO UPDATE
+		 SET status = 'waiting', waiting_since = NOW(), username = $3, rating = $4
+		 WHERE arena_players.status = 'idle'`,
+		arenaID, userID, username, rating,
+	)
+	return err
+}
+
+// LeaveArena removes a waiting player from the pairing pool. Their points
+// are kept.
+func (r *Repository) LeaveArena(ctx context.Context, arenaID, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE arena_players SET status = 'idle'
+		 WHERE arena_id = $1 AND user_id = $2 AND status = 'waiting'`,
+		arenaID, userID,
+	)
+	return err
+}
+
+// GetArenaPlayer loads one participant. Returns sql.ErrNoRows if the player
+// never joined the arena.
+func (r *Repository) GetArenaPlayer(ctx context.Context, arenaID, userID string) (*ArenaPlayer, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT user_id, username, rating, status, match_id, points, streak, games_played, waiting_since
+		 FROM arena_players WHERE arena_id = $1 AND user_id = $2`,
+		arenaID, userID,
+	)
+	return scanArenaPlayer(row)
+}
+
+// ListWaitingArenaPlayers returns everyone in the pairing pool, lowest
+// rating first.
+func (r *Repository) ListWaitingArenaPlayers(ctx context.Context, arenaID string) ([]*ArenaPlayer, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, username, rating, status, match_id, points, streak, games_played, waiting_since
+		 FROM arena_players WHERE arena_id = $1 AND status = 'waiting'
+		 ORDER BY rating`,
+		arenaID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := []*ArenaPlayer{}
+	for rows.Next() {
+		p, err := scanArenaPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	return players, rows.Err()
+}
+
+// ClaimArenaPair atomically moves two waiting players into a game. Returns
+// false if either was already taken by a concurrent pairing.
+func (r *Repository) ClaimArenaPair(ctx context.Context, arenaID, userA, userB string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE arena_players SET status = 'playing', match_id = NULL
+		 WHERE arena_id = $1 AND user_id IN ($2, $3) AND status = 'waiting'`,
+		arenaID, userA, userB,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 2 {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// SetArenaMatch records which match a claimed pair was sent to.
+func (r *Repository) SetArenaMatch(ctx context.Context, arenaID, matchID string, userIDs ...string) error {
+	for _, userID := range userIDs {
+		if _, err := r.db.ExecContext(ctx,
+			`UPDATE arena_players SET match_id = $3 WHERE arena_id = $1 AND user_id = $2`,
+			arenaID, userID, matchID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseArenaPlayers returns players to the pairing pool, e.g. when match
+// creation failed after they were claimed.
+func (r *Repository) ReleaseArenaPlayers(ctx context.Context, arenaID string, userIDs ...string) error {
+	for _, userID := range userIDs {
+		if _, err := r.db.ExecContext(ctx,
+			`UPDATE arena_players SET status = 'waiting', match_id = NULL
+			 WHERE arena_id = $1 AND user_id = $2`,
+			arenaID, userID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResetArenaPlayer moves a player the arena still has as playing matchID
+// back to idle, for a match that ended without a result. An empty matchID
+// matches a claim whose match was never recorded.
+func (r *Repository) ResetArenaPlayer(ctx context.Context, arenaID, userID, matchID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE arena_players SET status = 'idle', match_id = NULL
+		 WHERE arena_id = $1 AND user_id = $2 AND status = 'playing' AND COALESCE(match_id, '') = $3`,
+		arenaID, userID, matchID,
+	)
+	return err
+}
+
+// RecordArenaGame adds a finished game to a player's arena standing and
+// moves them to the given status. Returns the updated record.
+func (r *Repository) RecordArenaGame(ctx context.Context, arenaID, userID string, points, streak int, status string) (*ArenaPlayer, error) {
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE arena_players
+		 SET points = points + $3, streak = $4, games_played = games_played + 1,
+		     status = $5, match_id = NULL, waiting_since = NOW()
+		 WHERE arena_id = $1 AND user_id = $2
+		 RETURNING user_id, username, rating, status, match_id, points, streak, games_played, waiting_since`,
+		arenaID, userID, points, streak, status,
+	)
+	return scanArenaPlayer(row)
+}
+
+func scanArenaPlayer(row rowScanner) (*ArenaPlayer, error) {
+	var p ArenaPlayer
+	var username, matchID sql.NullString
+	if err := row.Scan(&p.UserID, &username, &p.Rating, &p.Status, &matchID, &p.Points, &p.Streak, &p.GamesPlayed, &p.WaitingSince); err != nil {
+		return nil, err
+	}
+	p.Username = username.String
+	p.MatchID = matchID.String
+	return &p, nil
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BlockedPlayer is one entry of a player's block list.
+type BlockedPlayer struct {
+	UserID    string
+	CreatedAt time.Time
+}
+
+// BlockSet holds the block relations among a group of players.
+type BlockSet map[[2]string]bool
+
+// Either reports whether a blocked b or b blocked a.
+func (bs BlockSet) Either(a, b string) bool {
+	return bs[[2]string{a, b}] || bs[[2]string{b, a}]
+}
+
+// BlockPlayer adds blocked to blocker's block list.
+func (r *Repository) BlockPlayer(ctx context.Context, blockerID, blockedID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO player_blocks (blocker_id, blocked_id)
+		 VALUES ($1, $2)
+		 ON CONFLICT (blocker_id, blocked_id) DO NOTHING`,
+		blockerID, blockedID,
+	)
+	return err
+}
+
+// UnblockPlayer removes blocked from blocker's block list.
+func (r *Repository) UnblockPlayer(ctx context.Context, blockerID, blockedID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM player_blocks WHERE blocker_id = $1 AND blocked_id = $2`,
+		blockerID, blockedID,
+	)
+	return err
+}
+
+// CountBlockedPlayers returns the size of a player's block list.
+func (r *Repository) CountBlockedPlayers(ctx context.Context, blockerID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM player_blocks WHERE blocker_id = $1`,
+		blockerID,
+	).Scan(&count)
+	return count, err
+}
+
+// ListBlockedPlayers returns a player's block list, most recent first.
+func (r *Repository) ListBlockedPlayers(ctx context.Context, blockerID string) ([]*BlockedPlayer, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT blocked_id, created_at FROM player_blocks
+		 WHERE blocker_id = $1
+		 ORDER BY created_at DESC`,
+		blockerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocked := []*BlockedPlayer{}
+	for rows.Next() {
+		var b BlockedPlayer
+		if err := rows.Scan(&b.UserID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, &b)
+	}
+	return blocked, rows.Err()
+}
+
+// IsBlockedEither reports whether either player has blocked the other.
+func (r *Repository) IsBlockedEither(ctx context.Context, a, b string) (bool, error) {
+	var blocked bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (
+		     SELECT 1 FROM player_blocks
+		     WHERE (blocker_id = $1 AND blocked_id = $2)
+		        OR (blocker_id = $2 AND blocked_id = $1)
+		 )`,
+		a, b,
+	).Scan(&blocked)
+	return blocked, err
+}
+
+// BlocksAmong returns every block relation where both players are in
+// userIDs.
+func (r *Repository) BlocksAmong(ctx context.Context, userIDs []string) (BlockSet, error) {
+	blocks := BlockSet{}
+	if len(userIDs) < 2 {
+		return blocks, nil
+	}
+
+	placeholders := make([]string, len(userIDs))
+	args := make([]interface{}, len(userIDs))
+	for i, id := range userIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	in := strings.Join(placeholders, ", ")
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT blocker_id, blocked_id FROM player_blocks
+		 WHERE blocker_id IN (`+in+`) AND blocked_id IN (`+in+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var blocker, blocked string
+		if err := rows.Scan(&blocker, &blocked); err != nil {
+			return nil, err
+		}
+		blocks[[2]string{blocker, blocked}] = true
+	}
+	return blocks, rows.Err()
+}
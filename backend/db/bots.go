@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// BotAccount is a Nakama account registered to play as an external bot.
+// LadderRating only moves in bot-vs-bot games.
+type BotAccount struct {
+	UserID       string
+	Name         string
+	Owner        string
+	Active       bool
+	Wins         int
+	Losses       int
+	Draws        int
+	LadderRating int
+	CreatedAt    time.Time
+}
+
+// RegisterBot marks an account as a bot, or reactivates and renames one
+// registered before. Its record is kept.
+func (r *Repository) RegisterBot(ctx context.Context, userID, name, owner string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO bot_accounts (user_id, name, owner)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET
+		   name = EXCLUDED.name, owner = EXCLUDED.owner, active = TRUE, updated_at = NOW()`,
+		userID, name, owner,
+	)
+	return err
+}
+
+// DeactivateBot stops a bot from joining matches. Returns false if the
+// account was never registered.
+func (r *Repository) DeactivateBot(ctx context.Context, userID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE bot_accounts SET active = FALSE, updated_at = NOW() WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetBotAccount loads a registered bot. Returns sql.ErrNoRows for accounts
+// that were never registered.
+func (r *Repository) GetBotAccount(ctx context.Context, userID string) (*BotAccount, error) {
+	var bot BotAccount
+	err := r.db.QueryRowContext(ctx,
+		`SELECT user_id, name, owner, active, total_wins, total_losses, total_draws, ladder_rating, created_at
+		 FROM bot_accounts WHERE user_id = $1`,
+		userID,
+	).Scan(&bot.UserID, &bot.Name, &bot.Owner, &bot.Active, &bot.Wins, &bot.Losses, &bot.Draws, &bot.LadderRating, &bot.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+// IsRegisteredBot reports whether the account has ever been registered as a
+// bot, active or not.
+func (r *Repository) IsRegisteredBot(ctx context.Context, userID string) (bool, error) {
+	_, err := r.GetBotAccount(ctx, userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// RecordBotResults adds a finished game to each bot's record in one
+// transaction and returns the updated accounts.
+func (r *Repository) RecordBotResults(ctx context.Context, results []GameResult) (map[string]*BotAccount, error) {
+	// Lock rows in a stable order so concurrent games can't deadlock.
+	sorted := append([]GameResult(nil), results...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].UserID < sorted[j].UserID })
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	updated := make(map[string]*BotAccount, len(sorted))
+	for _, res := range sorted {
+		win, loss, draw := outcomeCounts(res.Outcome)
+
+		bot := BotAccount{UserID: res.UserID, Active: true}
+		if err := tx.QueryRowContext(ctx,
+			`UPDATE bot_accounts SET
+			   total_wins    = total_wins + $2,
+			   total_losses  = total_losses + $3,
+			   total_draws   = total_draws + $4,
+			   ladder_rating = ladder_rating + $5,
+			   updated_at    = NOW()
+			 WHERE user_id = $1
+			 RETURNING name, owner, total_wins, total_losses, total_draws, ladder_rating, created_at`,
+			res.UserID, win, loss, draw, res.RatingDelta,
+		).Scan(&bot.Name, &bot.Owner, &bot.Wins, &bot.Losses, &bot.Draws, &bot.LadderRating, &bot.CreatedAt); err != nil {
+			return nil, err
+		}
+		updated[res.UserID] = &bot
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,257 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ClanWar is a scheduled event in which members of two clans are paired
+// against each other. Every result counts towards the clans' scores.
+// Participants move through the same statuses as arena players.
+type ClanWar struct {
+	WarID    string
+	ClanA    string
+	ClanB    string
+	Mode     string
+	StartsAt time.Time
+	EndsAt   time.Time
+	ScoreA   int
+	ScoreB   int
+}
+
+// IsRunning reports whether the war is accepting games at t.
+func (w *ClanWar) IsRunning(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// ClanWarPlayer is one member's participation in a clan war.
+type ClanWarPlayer struct {
+	UserID       string
+	ClanID       string
+	Username     string
+	Rating       int
+	Status       string
+	MatchID      string
+	Points       int
+	GamesPlayed  int
+	WaitingSince time.Time
+}
+
+// CreateClanWar inserts a new clan war.
+func (r *Repository) CreateClanWar(ctx context.Context, w *ClanWar) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO clan_wars (war_id, clan_a, clan_b, mode, starts_at, ends_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		w.WarID, w.ClanA, w.ClanB, w.Mode, w.StartsAt, w.EndsAt,
+	)
+	return err
+}
+
+// GetClanWar loads a clan war. Returns sql.ErrNoRows if it does not exist.
+func (r *Repository) GetClanWar(ctx context.Context, warID string) (*ClanWar, error) {
+	var w ClanWar
+	err := r.db.QueryRowContext(ctx,
+		`SELECT war_id, clan_a, clan_b, mode, starts_at, ends_at, score_a, score_b
+		 FROM clan_wars WHERE war_id = $1`,
+		warID,
+	).Scan(&w.WarID, &w.ClanA, &w.ClanB, &w.Mode, &w.StartsAt, &w.EndsAt, &w.ScoreA, &w.ScoreB)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ListClanWars returns the wars a clan is part of that have not ended yet,
+// soonest first.
+func (r *Repository) ListClanWars(ctx context.Context, clanID string) ([]*ClanWar, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT war_id, clan_a, clan_b, mode, starts_at, ends_at, score_a, score_b
+		 FROM clan_wars WHERE (clan_a = $1 OR clan_b = $1) AND ends_at > NOW()
+		 ORDER BY starts_at`,
+		clanID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wars := []*ClanWar{}
+	for rows.Next() {
+		var w ClanWar
+		if err := rows.Scan(&w.WarID, &w.ClanA, &w.ClanB, &w.Mode, &w.StartsAt, &w.EndsAt, &w.ScoreA, &w.ScoreB); err != nil {
+			return nil, err
+		}
+		wars = append(wars, &w)
+	}
+	return wars, rows.Err()
+}
+
+// JoinClanWar adds a member to the war's pairing pool, or puts a returning
+// idle member back into it. Members already in a game are left untouched.
+func (r *Repository) JoinClanWar(ctx context.Context, warID, userID, clanID, username string, rating int) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO clan_war_players (war_id, user_id, clan_id, username, rating, status, waiting_since)
+		 VALUES ($1, $2, $3, $4, $5, 'waiting', NOW())
+		 ON CONFLICT (war_id, user_id) DO UPDATE
+		 SET status = 'waiting', waiting_since = NOW(), username = $4, rating = $5
+		 WHERE clan_war_players.status = 'idle'`,
+		warID, userID, clanID, username, rating,
+	)
+	return err
+}
+
+// LeaveClanWar removes a waiting member from the pairing pool.
+func (r *Repository) LeaveClanWar(ctx context.Context, warID, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE clan_war_players SET status = 'idle'
+		 WHERE war_id = $1 AND user_id = $2 AND status = 'waiting'`,
+		warID, userID,
+	)
+	return err
+}
+
+// GetClanWarPlayer loads one participant. Returns sql.ErrNoRows if the
+// member never joined the war.
+func (r *Repository) GetClanWarPlayer(ctx context.Context, warID, userID string) (*ClanWarPlayer, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT user_id, clan_id, username, rating, status, match_id, points, games_played, waiting_since
+		 FROM clan_war_players WHERE war_id = $1 AND user_id = $2`,
+		warID, userID,
+	)
+	return scanClanWarPlayer(row)
+}
+
+// ListWaitingClanWarPlayers returns everyone in the war's pairing pool,
+// longest waiting first.
+func (r *Repository) ListWaitingClanWarPlayers(ctx context.Context, warID string) ([]*ClanWarPlayer, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, clan_id, username, rating, status, match_id, points, games_played, waiting_since
+		 FROM clan_war_players WHERE war_id = $1 AND status = 'waiting'
+		 ORDER BY waiting_since`,
+		warID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := []*ClanWarPlayer{}
+	for rows.Next() {
+		p, err := scanClanWarPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	return players, rows.Err()
+}
+
+// ClaimClanWarPair atomically moves two waiting members into a game.
+// Returns false if either was already taken by a concurrent pairing.
+func (r *Repository) ClaimClanWarPair(ctx context.Context, warID, userA, userB string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE clan_war_players SET status = 'playing', match_id = NULL
+		 WHERE war_id = $1 AND user_id IN ($2, $3) AND status = 'waiting'`,
+		warID, userA, userB,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 2 {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// SetClanWarMatch records which match a claimed pair was sent to.
+func (r *Repository) SetClanWarMatch(ctx context.Context, warID, matchID string, userIDs ...string) error {
+	for _, userID := range userIDs {
+		if _, err := r.db.ExecContext(ctx,
+			`UPDATE clan_war_players SET match_id = $3 WHERE war_id = $1 AND user_id = $2`,
+			warID, userID, matchID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseClanWarPlayers returns members to the pairing pool, e.g. when
+// match creation failed after they were claimed.
+func (r *Repository) ReleaseClanWarPlayers(ctx context.Context, warID string, userIDs ...string) error {
+	for _, userID := range userIDs {
+		if _, err := r.db.ExecContext(ctx,
+			`UPDATE clan_war_players SET status = 'waiting', match_id = NULL
+			 WHERE war_id = $1 AND user_id = $2`,
+			warID, userID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResetClanWarPlayer moves a member the war still has as playing matchID
+// back to idle, for a match that ended without a result. An empty matchID
+// matches a claim whose match was never recorded.
+func (r *Repository) ResetClanWarPlayer(ctx context.Context, warID, userID, matchID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE clan_war_players SET status = 'idle', match_id = NULL
+		 WHERE war_id = $1 AND user_id = $2 AND status = 'playing' AND COALESCE(match_id, '') = $3`,
+		warID, userID, matchID,
+	)
+	return err
+}
+
+// RecordClanWarGame adds a finished game to a member's war record and to
+// their clan's score in one transaction, then moves the member to the
+// given status.
+func (r *Repository) RecordClanWarGame(ctx context.Context, warID, userID string, points int, status string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var clanID string
+	err = tx.QueryRowContext(ctx,
+		`UPDATE clan_war_players
+		 SET points = points + $3, games_played = games_played + 1,
+		     status = $4, match_id = NULL, waiting_since = NOW()
+		 WHERE war_id = $1 AND user_id = $2
+		 RETURNING clan_id`,
+		warID, userID, points, status,
+	).Scan(&clanID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE clan_wars
+		 SET score_a = score_a + CASE WHEN clan_a = $2 THEN $3 ELSE 0 END,
+		     score_b = score_b + CASE WHEN clan_b = $2 THEN $3 ELSE 0 END
+		 WHERE war_id = $1`,
+		warID, clanID, points,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanClanWarPlayer(row rowScanner) (*ClanWarPlayer, error) {
+	var p ClanWarPlayer
+	var username, matchID sql.NullString
+	if err := row.Scan(&p.UserID, &p.ClanID, &username, &p.Rating, &p.Status, &matchID, &p.Points, &p.GamesPlayed, &p.WaitingSince); err != nil {
+		return nil, err
+	}
+	p.Username = username.String
+	p.MatchID = matchID.String
+	return &p, nil
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Correspondence game statuses.
+const (
+	CorrespondenceActive   = "active"
+	CorrespondenceFinished = "finished"
+)
+
+// ErrVersionConflict is returned when a row was modified by someone else
+// between read and write.
+var ErrVersionConflict = errors.New("version conflict")
+
+// CorrespondenceGame is a stored asynchronous game. State holds the
+// serialised match state exactly as the live match would broadcast it.
+type CorrespondenceGame struct {
+	GameID        string
+	PlayerXID     string
+	PlayerOID     string
+	CurrentTurnID string
+	Status        string
+	State         []byte
+	MoveDeadline  time.Time
+	Version       int
+}
+
+// CreateCorrespondenceGame inserts a new correspondence game.
+func (r *Repository) CreateCorrespondenceGame(ctx context.Context, g *CorrespondenceGame) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO correspondence_games
+		   (game_id, player_x_id, player_o_id, current_turn_id, status, state, move_deadline)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		g.GameID, g.PlayerXID, g.PlayerOID, g.CurrentTurnID, g.Status, string(g.State), g.MoveDeadline,
+	)
+	return err
+}
+
+// GetCorrespondenceGame loads a single game. Returns sql.ErrNoRows if it
+// does not exist.
+func (r *Repository) GetCorrespondenceGame(ctx context.Context, gameID string) (*CorrespondenceGame, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT game_id, player_x_id, player_o_id, current_turn_id, status, state, move_deadline, version
+		 FROM correspondence_games WHERE game_id = $1`,
+		gameID,
+	)
+	return scanCorrespondenceGame(row)
+}
+
+// ListCorrespondenceGames returns the player's games, most recently updated
+// first. Finished games are only included when includeFinished is set.
+func (r *Repository) ListCorrespondenceGames(ctx context.Context, userID string, includeFinished bool, limit int) ([]*CorrespondenceGame, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT game_id, player_x_id, player_o_id, current_turn_id, status, state, move_deadline, version
+		 FROM correspondence_games
+		 WHERE (player_x_id = $1 OR player_o_id = $1)
+		   AND ($2 OR status = 'active')
+		 ORDER BY updated_at DESC
+		 LIMIT $3`,
+		userID, includeFinished, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	games := []*CorrespondenceGame{}
+	for rows.Next() {
+		g, err := scanCorrespondenceGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+	return games, rows.Err()
+}
+
+// CountActiveCorrespondenceGames returns how many unfinished games the
+// player is currently part of.
+func (r *Repository) CountActiveCorrespondenceGames(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM correspondence_games
+		 WHERE (player_x_id = $1 OR player_o_id = $1) AND status = 'active'`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+// UpdateCorrespondenceGame saves a game using optimistic locking on the
+// version column. Returns ErrVersionConflict if the row changed since it
+// was read.
+func (r *Repository) UpdateCorrespondenceGame(ctx context.Context, g *CorrespondenceGame) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE correspondence_games
+		 SET current_turn_id = $2, status = $3, state = $4, move_deadline = $5,
+		     version = version + 1, updated_at = NOW()
+		 WHERE game_id = $1 AND version = $6`,
+		g.GameID, g.CurrentTurnID, g.Status, string(g.State), g.MoveDeadline, g.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrVersionConflict
+	}
+	g.Version++
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCorrespondenceGame(row rowScanner) (*CorrespondenceGame, error) {
+	var g CorrespondenceGame
+	var turnID sql.NullString
+	var deadline sql.NullTime
+	if err := row.Scan(&g.GameID, &g.PlayerXID, &g.PlayerOID, &turnID, &g.Status, &g.State, &deadline, &g.Version); err != nil {
+		return nil, err
+	}
+	g.CurrentTurnID = turnID.String
+	g.MoveDeadline = deadline.Time
+	return &g, nil
+}
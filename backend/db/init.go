@@ -6,6 +6,7 @@ import (
 	"embed"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -13,7 +14,9 @@ import (
 //go:embed migrations/*.sql
 var migrationFS embed.FS
 
-// InitializeDatabase runs all SQL migration files in order and creates therequired Nakama leaderboards.
+// InitializeDatabase runs all SQL migration files in order and creates the
+// required Nakama leaderboards. It runs once, from InitModule; nothing else
+// creates leaderboards.
 func InitializeDatabase(ctx context.Context, logger runtime.Logger, database *sql.DB, nk runtime.NakamaModule) error {
 	logger.Info("Running database migrations...")
 
@@ -63,17 +66,107 @@ func runMigrations(ctx context.Context, logger runtime.Logger, db *sql.DB) error
 	return nil
 }
 
-// EnsureLeaderboards creates the required leaderboards if they do not exist.
-// Safe to call multiple times — Nakama ignores duplicate creates.
+// Game modes. The match package's Mode constants are these values.
+const (
+	ModeClassic        = "classic"
+	ModeTimed          = "timed"
+	ModeCorrespondence = "correspondence"
+	ModeMisere         = "misere"
+	ModeVanishing      = "vanishing"
+)
+
+// Leaderboard IDs created here and written by the match package.
+const (
+	LeaderboardGlobalWins = "global_wins"
+	LeaderboardWinStreaks = "win_streaks"
+
+	// LeaderboardClanWins is the board of clan wins. Its owners are group
+	// IDs, so it has no per-mode or seasonal variants (season rewards go
+	// to players).
+	LeaderboardClanWins = "clan_wins"
+
+	// LeaderboardPuzzle ranks today's daily puzzle solvers by tries, then
+	// solve time, and resets at midnight UTC with the puzzle.
+	LeaderboardPuzzle = "daily_puzzle"
+
+	// LeaderboardBotLadder ranks external bots by their bot-vs-bot rating.
+	// Bots never appear on the player boards.
+	LeaderboardBotLadder = "bot_ladder"
+)
+
+// LeaderboardModes are the game modes that get their own all-time and
+// seasonal boards in addition to the overall ones.
+var LeaderboardModes = []string{ModeClassic, ModeTimed, ModeCorrespondence, ModeMisere, ModeVanishing}
+
+// SeasonResetSchedule is the cron schedule on which seasonal boards reset
+// (midnight UTC on the first of every month).
+const SeasonResetSchedule = "0 0 1 * *"
+
+const seasonLeaderboardPrefix = "season_"
+
+// leaderboardOperators maps each base leaderboard to its score operator.
+var leaderboardOperators = map[string]string{
+	LeaderboardGlobalWins: "incr",
+	LeaderboardWinStreaks: "set",
+}
+
+// PuzzleResetSchedule is the cron schedule on which the daily puzzle and
+// its leaderboard change.
+const PuzzleResetSchedule = "0 0 * * *"
+
+// ModeLeaderboardID returns the all-time board for base in mode, e.g.
+// "global_wins_timed".
+func ModeLeaderboardID(base, mode string) string {
+	return base + "_" + mode
+}
+
+// SeasonLeaderboardID returns the seasonal board for base in mode, e.g.
+// "season_global_wins_timed".
+func SeasonLeaderboardID(base, mode string) string {
+	return seasonLeaderboardPrefix + ModeLeaderboardID(base, mode)
+}
+
+// IsSeasonLeaderboard reports whether id is one of the seasonal boards.
+func IsSeasonLeaderboard(id string) bool {
+	return strings.HasPrefix(id, seasonLeaderboardPrefix)
+}
+
+// EnsureLeaderboards creates the required leaderboards if they do not exist:
+// the overall boards, plus an all-time and a seasonal board per mode.
+// Called once at startup; Nakama ignores creates of existing boards.
 func EnsureLeaderboards(logger runtime.Logger, nk runtime.NakamaModule) error {
 	ctx := context.Background()
 
-	if err := nk.LeaderboardCreate(ctx, "global_wins", true, "desc", "incr", "", nil); err != nil {
-		logger.Warn("global_wins leaderboard create (may already exist): %v", err)
+	for base, operator := range leaderboardOperators {
+		if err := nk.LeaderboardCreate(ctx, base, true, "desc", operator, "", nil); err != nil {
+			logger.Warn("%s leaderboard create (may already exist): %v", base, err)
+		}
+
+		for _, mode := range LeaderboardModes {
+			metadata := map[string]interface{}{"base": base, "mode": mode}
+
+			id := ModeLeaderboardID(base, mode)
+			if err := nk.LeaderboardCreate(ctx, id, true, "desc", operator, "", metadata); err != nil {
+				logger.Warn("%s leaderboard create (may already exist): %v", id, err)
+			}
+
+			id = SeasonLeaderboardID(base, mode)
+			if err := nk.LeaderboardCreate(ctx, id, true, "desc", operator, SeasonResetSchedule, metadata); err != nil {
+				logger.Warn("%s leaderboard create (may already exist): %v", id, err)
+			}
+		}
+	}
+
+	if err := nk.LeaderboardCreate(ctx, LeaderboardClanWins, true, "desc", "incr", "", nil); err != nil {
+		logger.Warn("%s leaderboard create (may already exist): %v", LeaderboardClanWins, err)
+	}
+
+	if err := nk.LeaderboardCreate(ctx, LeaderboardPuzzle, true, "asc", "best", PuzzleResetSchedule, nil); err != nil {
+		logger.Warn("%s leaderboard create (may already exist): %v", LeaderboardPuzzle, err)
 	}
 
-	if err := nk.LeaderboardCreate(ctx, "win_streaks", true, "desc", "set", "", nil); err != nil {
-		logger.Warn("win_streaks leaderboard create (may already exist): %v", err)
+	if err := nk.LeaderboardCreate(ctx, LeaderboardBotLadder, true, "desc", "set", "", nil); err != nil {
+		logger.Warn("%s leaderboard create (may already exist): %v", LeaderboardBotLadder, err)
 	}
 
 	logger.Info("Leaderboards ready")
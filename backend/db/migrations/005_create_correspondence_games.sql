@@ -0,0 +1,16 @@
+-- 005: Asynchronous correspondence games (one row per game, state as JSON)
+CREATE TABLE IF NOT EXISTS correspondence_games (
+    game_id           VARCHAR(255) PRIMARY KEY,
+    player_x_id       VARCHAR(255) NOT NULL,
+    player_o_id       VARCHAR(255) NOT NULL,
+    current_turn_id   VARCHAR(255),
+    status            VARCHAR(20)  DEFAULT 'active',
+    state             JSONB        NOT NULL,
+    move_deadline     TIMESTAMP,
+    version           INT          DEFAULT 0,
+    created_at        TIMESTAMP    DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP    DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_correspondence_player_x ON correspondence_games (player_x_id, status);
+CREATE INDEX IF NOT EXISTS idx_correspondence_player_o ON correspondence_games (player_o_id, status);
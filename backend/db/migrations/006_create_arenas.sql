@@ -0,0 +1,24 @@
+-- 006: Time-boxed arena events and their participants
+CREATE TABLE IF NOT EXISTS arenas (
+    arena_id   VARCHAR(255) PRIMARY KEY,
+    mode       VARCHAR(20),
+    starts_at  TIMESTAMP NOT NULL,
+    ends_at    TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS arena_players (
+    arena_id      VARCHAR(255) NOT NULL,
+    user_id       VARCHAR(255) NOT NULL,
+    username      VARCHAR(255),
+    rating        INT          DEFAULT 1000,
+    status        VARCHAR(20)  DEFAULT 'waiting',
+    match_id      VARCHAR(255),
+    points        INT          DEFAULT 0,
+    streak        INT          DEFAULT 0,
+    games_played  INT          DEFAULT 0,
+    waiting_since TIMESTAMP    DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (arena_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_arena_players_status ON arena_players (arena_id, status, rating);
@@ -0,0 +1,16 @@
+-- 007: Final standings of finished leaderboard seasons
+CREATE TABLE IF NOT EXISTS season_history (
+    leaderboard_id VARCHAR(255) NOT NULL,
+    season_id      VARCHAR(20)  NOT NULL,
+    user_id        VARCHAR(255) NOT NULL,
+    username       VARCHAR(255),
+    rank           BIGINT,
+    score          BIGINT,
+    reward         VARCHAR(50),
+    season_start   TIMESTAMP,
+    season_end     TIMESTAMP,
+    archived_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (leaderboard_id, season_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_season_history_user ON season_history (user_id, season_end DESC);
@@ -0,0 +1,13 @@
+-- 008: Lifetime win streaks and per-mode breakdown of player statistics
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS current_streak INT DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS best_streak    INT DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS player_mode_stats (
+    user_id    VARCHAR(255) NOT NULL,
+    mode       VARCHAR(20)  NOT NULL,
+    wins       INT       DEFAULT 0,
+    losses     INT       DEFAULT 0,
+    draws      INT       DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, mode)
+);
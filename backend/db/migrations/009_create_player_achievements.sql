@@ -0,0 +1,7 @@
+-- 009: Unlocked achievements per player
+CREATE TABLE IF NOT EXISTS player_achievements (
+    user_id        VARCHAR(255) NOT NULL,
+    achievement_id VARCHAR(64)  NOT NULL,
+    unlocked_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, achievement_id)
+);
@@ -0,0 +1,17 @@
+-- 010: XP totals and quest progress per player
+CREATE TABLE IF NOT EXISTS player_progress (
+    user_id    VARCHAR(255) PRIMARY KEY,
+    xp         BIGINT    NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per quest per period; a new period starts a fresh row, so
+-- quests reset without a cleanup job.
+CREATE TABLE IF NOT EXISTS player_quests (
+    user_id    VARCHAR(255) NOT NULL,
+    quest_id   VARCHAR(64)  NOT NULL,
+    period_key VARCHAR(16)  NOT NULL,
+    progress   INT       NOT NULL DEFAULT 0,
+    claimed_at TIMESTAMP,
+    PRIMARY KEY (user_id, quest_id, period_key)
+);
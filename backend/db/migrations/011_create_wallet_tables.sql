@@ -0,0 +1,26 @@
+-- 011: Wager escrows and daily login rewards
+-- Coin balances and the movement ledger live in Nakama's wallet; these
+-- tables track what is held on behalf of live matches so a crash can be
+-- recovered from.
+CREATE TABLE IF NOT EXISTS wager_escrows (
+    escrow_id  VARCHAR(64)  PRIMARY KEY,
+    match_id   VARCHAR(255) NOT NULL,
+    player_a   VARCHAR(255) NOT NULL,
+    player_b   VARCHAR(255) NOT NULL,
+    stake      BIGINT       NOT NULL,
+    status     VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    winner_id  VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    settled_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_wager_escrows_open
+    ON wager_escrows(status) WHERE status IN ('pending', 'held');
+
+CREATE TABLE IF NOT EXISTS daily_login_rewards (
+    user_id    VARCHAR(255) NOT NULL,
+    reward_day DATE         NOT NULL,
+    coins      BIGINT       NOT NULL,
+    claimed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, reward_day)
+);
@@ -0,0 +1,28 @@
+-- 012: Scheduled clan-vs-clan wars and their participants
+CREATE TABLE IF NOT EXISTS clan_wars (
+    war_id     VARCHAR(255) PRIMARY KEY,
+    clan_a     VARCHAR(255) NOT NULL,
+    clan_b     VARCHAR(255) NOT NULL,
+    mode       VARCHAR(20),
+    starts_at  TIMESTAMP NOT NULL,
+    ends_at    TIMESTAMP NOT NULL,
+    score_a    INT DEFAULT 0,
+    score_b    INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS clan_war_players (
+    war_id        VARCHAR(255) NOT NULL,
+    user_id       VARCHAR(255) NOT NULL,
+    clan_id       VARCHAR(255) NOT NULL,
+    username      VARCHAR(255),
+    rating        INT          DEFAULT 1000,
+    status        VARCHAR(20)  DEFAULT 'waiting',
+    match_id      VARCHAR(255),
+    points        INT          DEFAULT 0,
+    games_played  INT          DEFAULT 0,
+    waiting_since TIMESTAMP    DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (war_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_clan_war_players_status ON clan_war_players (war_id, status, clan_id);
@@ -0,0 +1,2 @@
+-- 013: Global chat ban, separate from the match ban
+ALTER TABLE player_status ADD COLUMN IF NOT EXISTS chat_banned BOOLEAN DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- 014: Tie chat messages to their match
+ALTER TABLE match_chat ADD COLUMN IF NOT EXISTS match_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_match_chat_match ON match_chat (match_id, created_at);
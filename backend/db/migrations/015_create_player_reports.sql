@@ -0,0 +1,28 @@
+-- 015: Player reports, offender flags and per-match move lists
+ALTER TABLE match_history ADD COLUMN IF NOT EXISTS players JSONB;
+ALTER TABLE match_history ADD COLUMN IF NOT EXISTS moves JSONB;
+
+ALTER TABLE player_status ADD COLUMN IF NOT EXISTS flagged BOOLEAN DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS player_reports (
+    report_id   VARCHAR(64)  PRIMARY KEY,
+    reporter_id VARCHAR(255) NOT NULL,
+    reported_id VARCHAR(255) NOT NULL,
+    match_id    VARCHAR(255) NOT NULL,
+    category    VARCHAR(32)  NOT NULL,
+    details     TEXT,
+    transcript  JSONB,
+    moves       JSONB,
+    status      VARCHAR(16)  NOT NULL DEFAULT 'open',
+    claimed_by  VARCHAR(255),
+    action      VARCHAR(16),
+    resolution  TEXT,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    claimed_at  TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_player_reports_unique
+    ON player_reports (reporter_id, reported_id, match_id);
+CREATE INDEX IF NOT EXISTS idx_player_reports_queue ON player_reports (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_player_reports_reported ON player_reports (reported_id, created_at);
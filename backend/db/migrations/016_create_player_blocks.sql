@@ -0,0 +1,9 @@
+-- 016: Per-user block lists
+CREATE TABLE IF NOT EXISTS player_blocks (
+    blocker_id VARCHAR(255) NOT NULL,
+    blocked_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_blocks_blocked ON player_blocks (blocked_id);
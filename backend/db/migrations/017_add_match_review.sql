@@ -0,0 +1,2 @@
+-- 017: Solver review of each recorded game, computed once
+ALTER TABLE match_history ADD COLUMN IF NOT EXISTS review JSONB;
@@ -0,0 +1,2 @@
+-- 018: Games where a player used a hint
+ALTER TABLE match_history ADD COLUMN IF NOT EXISTS used_hints BOOLEAN DEFAULT FALSE;
@@ -0,0 +1,11 @@
+-- 019: Daily puzzle progress, one row per player per puzzle
+CREATE TABLE IF NOT EXISTS puzzle_attempts (
+    user_id     VARCHAR(255) NOT NULL,
+    puzzle_date DATE         NOT NULL,
+    started_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts    INT          NOT NULL DEFAULT 0,
+    moves       JSONB        NOT NULL DEFAULT '[]',
+    solved_at   TIMESTAMP,
+    solve_ms    BIGINT,
+    PRIMARY KEY (user_id, puzzle_date)
+);
@@ -0,0 +1,13 @@
+-- 020: Registered external bot accounts and their bot-vs-bot ladder record
+CREATE TABLE IF NOT EXISTS bot_accounts (
+    user_id       VARCHAR(255) PRIMARY KEY,
+    name          VARCHAR(64)  NOT NULL,
+    owner         VARCHAR(255) NOT NULL DEFAULT '',
+    active        BOOLEAN      NOT NULL DEFAULT TRUE,
+    total_wins    INT          NOT NULL DEFAULT 0,
+    total_losses  INT          NOT NULL DEFAULT 0,
+    total_draws   INT          NOT NULL DEFAULT 0,
+    ladder_rating INT          NOT NULL DEFAULT 1000,
+    created_at    TIMESTAMP    DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP    DEFAULT CURRENT_TIMESTAMP
+);
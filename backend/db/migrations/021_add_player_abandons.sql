@@ -0,0 +1,2 @@
+-- 021: Games a player abandoned to the engine in casual matches
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS total_abandons INT DEFAULT 0;
@@ -0,0 +1,6 @@
+-- 022: One history row per game, so rematches in the same match are kept
+ALTER TABLE match_history ADD COLUMN IF NOT EXISTS game_index INT NOT NULL DEFAULT 0;
+
+ALTER TABLE match_history DROP CONSTRAINT IF EXISTS match_history_pkey;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_match_history_game ON match_history (match_id, game_index);
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Quest claim errors.
+var (
+	ErrQuestIncomplete     = errors.New("quest not complete")
+	ErrQuestAlreadyClaimed = errors.New("quest already claimed")
+)
+
+// QuestProgress is a player's progress on one quest in one period.
+type QuestProgress struct {
+	Progress int
+	Claimed  bool
+}
+
+// GetXP returns a player's total XP (0 if they have never earned any).
+func (r *Repository) GetXP(ctx context.Context, userID string) (int64, error) {
+	var xp int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT xp FROM player_progress WHERE user_id = $1`,
+		userID,
+	).Scan(&xp)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return xp, err
+}
+
+// AddXP adds xp to a player's total and returns the totals before and
+// after.
+func (r *Repository) AddXP(ctx context.Context, userID string, xp int64) (before, after int64, err error) {
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO player_progress (user_id, xp, updated_at)
+		 VALUES ($1, $2, NOW())
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET xp = player_progress.xp + $2, updated_at = NOW()
+		 RETURNING xp`,
+		userID, xp,
+	).Scan(&after)
+	return after - xp, after, err
+}
+
+// AdvanceQuest adds one to a player's progress on a quest, capped at
+// target. Claimed quests are left alone.
+func (r *Repository) AdvanceQuest(ctx context.Context, userID, questID, periodKey string, target int) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO player_quests (user_id, quest_id, period_key, progress)
+		 VALUES ($1, $2, $3, 1)
+		 ON CONFLICT (user_id, quest_id, period_key) DO UPDATE
+		 SET progress = LEAST(player_quests.progress + 1, $4)
+		 WHERE player_quests.claimed_at IS NULL`,
+		userID, questID, periodKey, target,
+	)
+	return err
+}
+
+// GetQuestProgress returns the player's progress on the given quests in
+// the given periods, keyed by quest ID. Quests with no progress are
+// omitted.
+func (r *Repository) GetQuestProgress(ctx context.Context, userID string, periodKeys []string) (map[string]QuestProgress, error) {
+	progress := make(map[string]QuestProgress)
+	for _, key := range periodKeys {
+		rows, err := r.db.QueryContext(ctx,
+			`SELECT quest_id, progress, claimed_at IS NOT NULL
+			 FROM player_quests WHERE user_id = $1 AND period_key = $2`,
+			userID, key,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var id string
+			var p QuestProgress
+			if err := rows.Scan(&id, &p.Progress, &p.Claimed); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			progress[id] = p
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return progress, nil
+}
+
+// ClaimQuest marks a completed quest as claimed and credits its XP reward
+// in one transaction. Returns the new XP total.
+func (r *Repository) ClaimQuest(ctx context.Context, userID, questID, periodKey string, target int, rewardXP int64) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var progress int
+	var claimed bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT progress, claimed_at IS NOT NULL FROM player_quests
+		 WHERE user_id = $1 AND quest_id = $2 AND period_key = $3
+		 FOR UPDATE`,
+		userID, questID, periodKey,
+	).Scan(&progress, &claimed)
+	if err == sql.ErrNoRows {
+		return 0, ErrQuestIncomplete
+	}
+	if err != nil {
+		return 0, err
+	}
+	if claimed {
+		return 0, ErrQuestAlreadyClaimed
+	}
+	if progress < target {
+		return 0, ErrQuestIncomplete
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE player_quests SET claimed_at = NOW()
+		 WHERE user_id = $1 AND quest_id = $2 AND period_key = $3`,
+		userID, questID, periodKey,
+	); err != nil {
+		return 0, err
+	}
+
+	var xp int64
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO player_progress (user_id, xp, updated_at)
+		 VALUES ($1, $2, NOW())
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET xp = player_progress.xp + $2, updated_at = NOW()
+		 RETURNING xp`,
+		userID, rewardXP,
+	).Scan(&xp); err != nil {
+		return 0, err
+	}
+	return xp, tx.Commit()
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PuzzleAttempt is a player's progress on one daily puzzle. Moves are the
+// positions played so far in the current try, the engine's replies
+// included. Attempts counts failed tries.
+type PuzzleAttempt struct {
+	UserID    string
+	Date      string
+	StartedAt time.Time
+	Attempts  int
+	Moves     []byte
+	Solved    bool
+	SolveMs   int64
+}
+
+// StartPuzzleAttempt returns the player's attempt at the puzzle for date
+// (YYYY-MM-DD), starting the clock on first call.
+func (r *Repository) StartPuzzleAttempt(ctx context.Context, userID, date string) (*PuzzleAttempt, error) {
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT INTO puzzle_attempts (user_id, puzzle_date)
+		 VALUES ($1, $2)
+		 ON CONFLICT (user_id, puzzle_date) DO NOTHING`,
+		userID, date,
+	); err != nil {
+		return nil, err
+	}
+
+	a := PuzzleAttempt{UserID: userID, Date: date}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT started_at, attempts, moves, solved_at IS NOT NULL, COALESCE(solve_ms, 0)
+		 FROM puzzle_attempts WHERE user_id = $1 AND puzzle_date = $2`,
+		userID, date,
+	).Scan(&a.StartedAt, &a.Attempts, &a.Moves, &a.Solved, &a.SolveMs)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// UpdatePuzzleProgress stores the current try. Returns false if the puzzle
+// was solved in the meantime.
+func (r *Repository) UpdatePuzzleProgress(ctx context.Context, userID, date string, moves []byte, attempts int) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE puzzle_attempts SET moves = $3, attempts = $4
+		 WHERE user_id = $1 AND puzzle_date = $2 AND solved_at IS NULL`,
+		userID, date, string(moves), attempts,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// MarkPuzzleSolved records the solve and its time since the attempt
+// started. Returns false if it was already solved.
+func (r *Repository) MarkPuzzleSolved(ctx context.Context, userID, date string, moves []byte) (solveMs int64, ok bool, err error) {
+	err = r.db.QueryRowContext(ctx,
+		`UPDATE puzzle_attempts
+		 SET moves = $3, solved_at = NOW(),
+		     solve_ms = (EXTRACT(EPOCH FROM (NOW() - started_at)) * 1000)::BIGINT
+		 WHERE user_id = $1 AND puzzle_date = $2 AND solved_at IS NULL
+		 RETURNING solve_ms`,
+		userID, date, string(moves),
+	).Scan(&solveMs)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return solveMs, err == nil, err
+}
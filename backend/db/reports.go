@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Report statuses. Reports are claimed by one moderator before they are
+// resolved.
+const (
+	ReportOpen     = "open"
+	ReportClaimed  = "claimed"
+	ReportResolved = "resolved"
+)
+
+// Moderator actions taken when a report is resolved.
+const (
+	ReportActionNone    = "none"
+	ReportActionChatBan = "chat_ban"
+	ReportActionBan     = "ban"
+)
+
+// Report is a player report with the evidence captured when it was filed.
+// Transcript and Moves are JSON documents.
+type Report struct {
+	ReportID        string
+	ReporterID      string
+	ReportedID      string
+	MatchID         string
+	Category        string
+	Details         string
+	Transcript      []byte
+	Moves           []byte
+	Status          string
+	ClaimedBy       string
+	Action          string
+	Resolution      string
+	CreatedAt       time.Time
+	ReportedFlagged bool
+}
+
+// CreateReport queues a new report. Returns false if the reporter already
+// reported this player for this match.
+func (r *Repository) CreateReport(ctx context.Context, rep *Report) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO player_reports
+		     (report_id, reporter_id, reported_id, match_id, category, details, transcript, moves)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (reporter_id, reported_id, match_id) DO NOTHING`,
+		rep.ReportID, rep.ReporterID, rep.ReportedID, rep.MatchID, rep.Category, rep.Details,
+		nullJSON(rep.Transcript), nullJSON(rep.Moves),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetReport loads one report. Returns sql.ErrNoRows if it does not exist.
+func (r *Repository) GetReport(ctx context.Context, reportID string) (*Report, error) {
+	row := r.db.QueryRowContext(ctx, reportSelect+` WHERE r.report_id = $1`, reportID)
+	return scanReport(row)
+}
+
+// ListReports returns reports in the given status, oldest first, with
+// flagged offenders ahead of everyone else.
+func (r *Repository) ListReports(ctx context.Context, status string, limit int) ([]*Report, error) {
+	rows, err := r.db.QueryContext(ctx,
+		reportSelect+` WHERE r.status = $1
+		 ORDER BY COALESCE(ps.flagged, FALSE) DESC, r.created_at
+		 LIMIT $2`,
+		status, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []*Report{}
+	for rows.Next() {
+		rep, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, rep)
+	}
+	return reports, rows.Err()
+}
+
+// ClaimReport assigns an open report to a moderator. Returns false if it
+// was not open.
+func (r *Repository) ClaimReport(ctx context.Context, reportID, moderator string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE player_reports SET status = 'claimed', claimed_by = $2, claimed_at = NOW()
+		 WHERE report_id = $1 AND status = 'open'`,
+		reportID, moderator,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ResolveReport closes a report claimed by the moderator and applies the
+// action to the reported player in the same transaction, so a report is
+// never left resolved with its action unapplied. Returns false if the
+// report is not claimed by them.
+func (r *Repository) ResolveReport(ctx context.Context, reportID, moderator, action, resolution string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var reportedID string
+	err = tx.QueryRowContext(ctx,
+		`UPDATE player_reports
+		 SET status = 'resolved', action = $3, resolution = $4, resolved_at = NOW()
+		 WHERE report_id = $1 AND status = 'claimed' AND claimed_by = $2
+		 RETURNING reported_id`,
+		reportID, moderator, action, resolution,
+	).Scan(&reportedID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch action {
+	case ReportActionBan:
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO player_status (user_id, is_banned)
+			 VALUES ($1, true)
+			 ON CONFLICT (user_id) DO UPDATE SET is_banned = true`,
+			reportedID,
+		)
+	case ReportActionChatBan:
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO player_status (user_id, chat_banned)
+			 VALUES ($1, true)
+			 ON CONFLICT (user_id) DO UPDATE SET chat_banned = true`,
+			reportedID,
+		)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// RefreshOffenderFlag flags a player once enough distinct players have
+// reported them within the window, or once enough reports against them
+// were acted on. Flags are only ever set here, never cleared. Returns the
+// player's flag.
+func (r *Repository) RefreshOffenderFlag(ctx context.Context, userID string, window time.Duration, minReporters, minActioned int) (bool, error) {
+	var flagged bool
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO player_status (user_id, flagged)
+		 SELECT $1, (
+		     (SELECT COUNT(DISTINCT reporter_id) FROM player_reports
+		      WHERE reported_id = $1 AND created_at > $2) >= $3
+		  OR (SELECT COUNT(*) FROM player_reports
+		      WHERE reported_id = $1 AND status = 'resolved' AND action <> 'none') >= $4
+		 )
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET flagged = player_status.flagged OR EXCLUDED.flagged
+		 RETURNING flagged`,
+		userID, time.Now().Add(-window), minReporters, minActioned,
+	).Scan(&flagged)
+	return flagged, err
+}
+
+const reportSelect = `SELECT r.report_id, r.reporter_id, r.reported_id, r.match_id, r.category,
+	     COALESCE(r.details, ''), COALESCE(r.transcript, '[]'), COALESCE(r.moves, '[]'),
+	     r.status, COALESCE(r.claimed_by, ''), COALESCE(r.action, ''), COALESCE(r.resolution, ''),
+	     r.created_at, COALESCE(ps.flagged, FALSE)
+	 FROM player_reports r
+	 LEFT JOIN player_status ps ON ps.user_id = r.reported_id`
+
+func scanReport(row rowScanner) (*Report, error) {
+	var rep Report
+	err := row.Scan(&rep.ReportID, &rep.ReporterID, &rep.ReportedID, &rep.MatchID, &rep.Category,
+		&rep.Details, &rep.Transcript, &rep.Moves,
+		&rep.Status, &rep.ClaimedBy, &rep.Action, &rep.Resolution,
+		&rep.CreatedAt, &rep.ReportedFlagged)
+	if err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
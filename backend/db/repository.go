@@ -7,6 +7,9 @@ import (
 	"time"
 )
 
+// DefaultSkillRating matches the player_stats.skill_rating column default.
+const DefaultSkillRating = 1000
+
 // Repository handles all direct database operations. SQL lives here,
 // not in the service or RPC layers.
 type Repository struct {
@@ -54,33 +57,156 @@ func (r *Repository) UnbanPlayer(ctx context.Context, userID string) error {
 	return err
 }
 
+// IsChatBanned returns true if the player is banned from chat.
+func (r *Repository) IsChatBanned(ctx context.Context, userID string) (bool, error) {
+	var banned bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT chat_banned FROM player_status WHERE user_id = $1`,
+		userID,
+	).Scan(&banned)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return banned, err
+}
+
+// SetChatBan sets or clears a player's chat ban (upsert).
+func (r *Repository) SetChatBan(ctx context.Context, userID string, banned bool) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO player_status (user_id, chat_banned)
+		 VALUES ($1, $2)
+		 ON CONFLICT (user_id) DO UPDATE SET chat_banned = $2`,
+		userID, banned,
+	)
+	return err
+}
+
 // Match history
 
-// RecordMatchResult persists the outcome of a completed match.
-func (r *Repository) RecordMatchResult(ctx context.Context, matchID, winnerID string, loserID *string, mode string, startTime int64) error {
-	duration := max(int(time.Now().Unix()-startTime), 0)
+// MatchRecord is a completed game as stored in match_history. GameIndex
+// counts the games played in the match, 0 for the first and one more for
+// each rematch. Players, Moves and Review are JSON documents encoded by the
+// match package.
+type MatchRecord struct {
+	MatchID   string
+	GameIndex int
+	WinnerID  string
+	LoserID   *string
+	Mode      string
+	StartTime int64
+	Players   []byte
+	Moves     []byte
+	Review    []byte
+	UsedHints bool
+}
+
+// LatestGame selects the most recent game of a match in GetMatchRecord.
+const LatestGame = -1
+
+// RecordMatchResult persists the outcome of a completed game.
+func (r *Repository) RecordMatchResult(ctx context.Context, rec *MatchRecord) error {
+	duration := max(int(time.Now().Unix()-rec.StartTime), 0)
 
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO match_history (match_id, winner_id, loser_id, mode, duration_seconds)
-		 VALUES ($1, $2, $3, $4, $5)
-		 ON CONFLICT (match_id) DO NOTHING`,
-		matchID, winnerID, loserID, mode, duration,
+		`INSERT INTO match_history (match_id, game_index, winner_id, loser_id, mode, duration_seconds, players, moves, review, used_hints)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 ON CONFLICT (match_id, game_index) DO NOTHING`,
+		rec.MatchID, rec.GameIndex, rec.WinnerID, rec.LoserID, rec.Mode, duration,
+		nullJSON(rec.Players), nullJSON(rec.Moves), nullJSON(rec.Review), rec.UsedHints,
 	)
 	return err
 }
 
+// GetMatchRecord loads one completed game of a match, or its latest game
+// for LatestGame. Returns sql.ErrNoRows if it was never recorded.
+func (r *Repository) GetMatchRecord(ctx context.Context, matchID string, gameIndex int) (*MatchRecord, error) {
+	var rec MatchRecord
+	var winnerID, loserID, mode sql.NullString
+	err := r.db.QueryRowContext(ctx,
+		`SELECT match_id, game_index, winner_id, loser_id, mode, COALESCE(players, '[]'), COALESCE(moves, '[]'), review,
+		        COALESCE(used_hints, FALSE)
+		 FROM match_history
+		 WHERE match_id = $1 AND ($2 < 0 OR game_index = $2)
+		 ORDER BY game_index DESC
+		 LIMIT 1`,
+		matchID, gameIndex,
+	).Scan(&rec.MatchID, &rec.GameIndex, &winnerID, &loserID, &mode, &rec.Players, &rec.Moves, &rec.Review, &rec.UsedHints)
+	if err != nil {
+		return nil, err
+	}
+	rec.WinnerID = winnerID.String
+	rec.Mode = mode.String
+	if loserID.Valid {
+		rec.LoserID = &loserID.String
+	}
+	return &rec, nil
+}
+
+// SetMatchReview stores a review for a game recorded without one. An
+// existing review is kept.
+func (r *Repository) SetMatchReview(ctx context.Context, matchID string, gameIndex int, review []byte) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE match_history SET review = $3 WHERE match_id = $1 AND game_index = $2 AND review IS NULL`,
+		matchID, gameIndex, string(review),
+	)
+	return err
+}
+
+// nullJSON stores an empty document as NULL.
+func nullJSON(doc []byte) interface{} {
+	if len(doc) == 0 {
+		return nil
+	}
+	return string(doc)
+}
+
 // Chat
 
-// InsertChatMessage stores a chat message.
-func (r *Repository) InsertChatMessage(ctx context.Context, userID, username, message string) error {
+// ChatMessage is one stored chat line.
+type ChatMessage struct {
+	MatchID   string
+	UserID    string
+	Username  string
+	Message   string
+	CreatedAt time.Time
+}
+
+// InsertChatMessage stores a chat message with the time it was broadcast.
+func (r *Repository) InsertChatMessage(ctx context.Context, m *ChatMessage) error {
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO match_chat (user_id, username, message, created_at)
-		 VALUES ($1, $2, $3, NOW())`,
-		userID, username, message,
+		`INSERT INTO match_chat (match_id, user_id, username, message, created_at)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		m.MatchID, m.UserID, m.Username, m.Message, m.CreatedAt,
 	)
 	return err
 }
 
+// GetChatTranscript returns a match's chat in the order it was sent.
+func (r *Repository) GetChatTranscript(ctx context.Context, matchID string, limit int) ([]*ChatMessage, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT match_id, user_id, username, message, created_at
+		 FROM match_chat WHERE match_id = $1
+		 ORDER BY created_at, id
+		 LIMIT $2`,
+		matchID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*ChatMessage{}
+	for rows.Next() {
+		var m ChatMessage
+		if err := rows.Scan(&m.MatchID, &m.UserID, &m.Username, &m.Message, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+	return messages, rows.Err()
+}
+
 // Helpers
 
 // GenerateFallbackMatchID builds a match ID when the real one is unavailable.
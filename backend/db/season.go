@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SeasonStanding is one player's final placing in an archived season.
+type SeasonStanding struct {
+	LeaderboardID string
+	SeasonID      string
+	UserID        string
+	Username      string
+	Rank          int64
+	Score         int64
+	Reward        string
+	SeasonStart   time.Time
+	SeasonEnd     time.Time
+}
+
+// SeasonSummary identifies one archived season of a leaderboard.
+type SeasonSummary struct {
+	SeasonID    string
+	SeasonStart time.Time
+	SeasonEnd   time.Time
+}
+
+// ArchiveSeasonStandings stores a finished season's final standings in one
+// transaction. Re-archiving the same season is a no-op.
+func (r *Repository) ArchiveSeasonStandings(ctx context.Context, standings []SeasonStanding) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, s := range standings {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO season_history
+			   (leaderboard_id, season_id, user_id, username, rank, score, reward, season_start, season_end)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			 ON CONFLICT (leaderboard_id, season_id, user_id) DO NOTHING`,
+			s.LeaderboardID, s.SeasonID, s.UserID, s.Username, s.Rank, s.Score, s.Reward, s.SeasonStart, s.SeasonEnd,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListSeasons returns the archived seasons of a leaderboard, newest first.
+func (r *Repository) ListSeasons(ctx context.Context, leaderboardID string, limit int) ([]SeasonSummary, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT season_id, MIN(season_start), MAX(season_end)
+		 FROM season_history WHERE leaderboard_id = $1
+		 GROUP BY season_id
+		 ORDER BY MAX(season_end) DESC
+		 LIMIT $2`,
+		leaderboardID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []SeasonSummary{}
+	for rows.Next() {
+		var s SeasonSummary
+		if err := rows.Scan(&s.SeasonID, &s.SeasonStart, &s.SeasonEnd); err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, s)
+	}
+	return seasons, rows.Err()
+}
+
+// GetSeasonStandings returns the top of an archived season, best rank first.
+func (r *Repository) GetSeasonStandings(ctx context.Context, leaderboardID, seasonID string, limit int) ([]SeasonStanding, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT leaderboard_id, season_id, user_id, username, rank, score, reward, season_start, season_end
+		 FROM season_history WHERE leaderboard_id = $1 AND season_id = $2
+		 ORDER BY rank
+		 LIMIT $3`,
+		leaderboardID, seasonID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []SeasonStanding{}
+	for rows.Next() {
+		s, err := scanSeasonStanding(rows)
+		if err != nil {
+			return nil, err
+		}
+		standings = append(standings, *s)
+	}
+	return standings, rows.Err()
+}
+
+// GetSeasonStanding returns one player's placing in an archived season.
+// Returns sql.ErrNoRows if they did not place.
+func (r *Repository) GetSeasonStanding(ctx context.Context, leaderboardID, seasonID, userID string) (*SeasonStanding, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT leaderboard_id, season_id, user_id, username, rank, score, reward, season_start, season_end
+		 FROM season_history WHERE leaderboard_id = $1 AND season_id = $2 AND user_id = $3`,
+		leaderboardID, seasonID, userID,
+	)
+	return scanSeasonStanding(row)
+}
+
+func scanSeasonStanding(row rowScanner) (*SeasonStanding, error) {
+	var s SeasonStanding
+	var username, reward sql.NullString
+	if err := row.Scan(&s.LeaderboardID, &s.SeasonID, &s.UserID, &username, &s.Rank, &s.Score, &reward, &s.SeasonStart, &s.SeasonEnd); err != nil {
+		return nil, err
+	}
+	s.Username = username.String
+	s.Reward = reward.String
+	return &s, nil
+}
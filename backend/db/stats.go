@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+)
+
+// Game outcomes from one player's point of view.
+const (
+	OutcomeWin  = "win"
+	OutcomeLoss = "loss"
+	OutcomeDraw = "draw"
+)
+
+// PlayerStats are a player's lifetime totals.
+type PlayerStats struct {
+	UserID        string
+	Wins          int
+	Losses        int
+	Draws         int
+	CurrentStreak int
+	BestStreak    int
+	SkillRating   int
+	Abandons      int
+}
+
+// ModeStats are a player's totals in a single game mode.
+type ModeStats struct {
+	Mode   string
+	Wins   int
+	Losses int
+	Draws  int
+}
+
+// GameResult is one player's outcome of a finished game. Abandoned marks
+// a loss by leaving a casual game to the engine.
+type GameResult struct {
+	UserID      string
+	Outcome     string
+	RatingDelta int
+	Abandoned   bool
+}
+
+// GetPlayerStats returns a player's lifetime totals. Players who have not
+// finished a game yet get zero totals and the default rating.
+func (r *Repository) GetPlayerStats(ctx context.Context, userID string) (*PlayerStats, error) {
+	stats := PlayerStats{UserID: userID, SkillRating: DefaultSkillRating}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT total_wins, total_losses, total_draws, current_streak, best_streak, skill_rating,
+		        COALESCE(total_abandons, 0)
+		 FROM player_stats WHERE user_id = $1`,
+		userID,
+	).Scan(&stats.Wins, &stats.Losses, &stats.Draws, &stats.CurrentStreak, &stats.BestStreak, &stats.SkillRating, &stats.Abandons)
+	if err == sql.ErrNoRows {
+		return &stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetSkillRating returns the player's skill rating, or the default for
+// players without a stats row.
+func (r *Repository) GetSkillRating(ctx context.Context, userID string) (int, error) {
+	var rating int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT skill_rating FROM player_stats WHERE user_id = $1`,
+		userID,
+	).Scan(&rating)
+	if err == sql.ErrNoRows {
+		return DefaultSkillRating, nil
+	}
+	return rating, err
+}
+
+// GetModeStats returns a player's per-mode totals, ordered by mode.
+func (r *Repository) GetModeStats(ctx context.Context, userID string) ([]ModeStats, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT mode, wins, losses, draws FROM player_mode_stats
+		 WHERE user_id = $1 ORDER BY mode`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	modes := []ModeStats{}
+	for rows.Next() {
+		var m ModeStats
+		if err := rows.Scan(&m.Mode, &m.Wins, &m.Losses, &m.Draws); err != nil {
+			return nil, err
+		}
+		modes = append(modes, m)
+	}
+	return modes, rows.Err()
+}
+
+// RecordGameResults applies every player's outcome of one game — lifetime
+// totals, streaks, rating and per-mode totals — in a single transaction,
+// and returns the updated lifetime stats keyed by user ID.
+func (r *Repository) RecordGameResults(ctx context.Context, mode string, results []GameResult) (map[string]*PlayerStats, error) {
+	// Lock rows in a stable order so concurrent games can't deadlock.
+	sorted := append([]GameResult(nil), results...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].UserID < sorted[j].UserID })
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	updated := make(map[string]*PlayerStats, len(sorted))
+	for _, res := range sorted {
+		win, loss, draw := outcomeCounts(res.Outcome)
+
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO player_stats (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING`,
+			res.UserID,
+		); err != nil {
+			return nil, err
+		}
+
+		stats := PlayerStats{UserID: res.UserID}
+		if err := tx.QueryRowContext(ctx,
+			`UPDATE player_stats SET
+			   total_wins     = total_wins + $2,
+			   total_losses   = total_losses + $3,
+			   total_draws    = total_draws + $4,
+			   current_streak = CASE WHEN $2 > 0 THEN current_streak + 1
+			                         WHEN $3 > 0 THEN 0
+			                         ELSE current_streak END,
+			   best_streak    = GREATEST(best_streak, CASE WHEN $2 > 0 THEN current_streak + 1 ELSE 0 END),
+			   skill_rating   = skill_rating + $5,
+			   total_abandons = COALESCE(total_abandons, 0) + $6,
+			   updated_at     = NOW()
+			 WHERE user_id = $1
+			 RETURNING total_wins, total_losses, total_draws, current_streak, best_streak, skill_rating, total_abandons`,
+			res.UserID, win, loss, draw, res.RatingDelta, abandonCount(res.Abandoned),
+		).Scan(&stats.Wins, &stats.Losses, &stats.Draws, &stats.CurrentStreak, &stats.BestStreak, &stats.SkillRating, &stats.Abandons); err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO player_mode_stats (user_id, mode, wins, losses, draws)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (user_id, mode) DO UPDATE SET
+			   wins       = player_mode_stats.wins + EXCLUDED.wins,
+			   losses     = player_mode_stats.losses + EXCLUDED.losses,
+			   draws      = player_mode_stats.draws + EXCLUDED.draws,
+			   updated_at = NOW()`,
+			res.UserID, mode, win, loss, draw,
+		); err != nil {
+			return nil, err
+		}
+
+		updated[res.UserID] = &stats
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func outcomeCounts(outcome string) (win, loss, draw int) {
+	switch outcome {
+	case OutcomeWin:
+		return 1, 0, 0
+	case OutcomeLoss:
+		return 0, 1, 0
+	default:
+		return 0, 0, 1
+	}
+}
+
+func abandonCount(abandoned bool) int {
+	if abandoned {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Wager escrow statuses. An escrow is pending until both stakes have been
+// debited, held while the game is played, and then moves to exactly one
+// final status.
+const (
+	EscrowPending   = "pending"
+	EscrowHeld      = "held"
+	EscrowPaid      = "paid"
+	EscrowRefunded  = "refunded"
+	EscrowCancelled = "cancelled"
+)
+
+// WagerEscrow is the stake both players of a wagered game put up.
+type WagerEscrow struct {
+	EscrowID string
+	MatchID  string
+	PlayerA  string
+	PlayerB  string
+	Stake    int64
+	Status   string
+	WinnerID string
+}
+
+// CreateEscrow records a pending escrow before any coins are moved.
+func (r *Repository) CreateEscrow(ctx context.Context, e *WagerEscrow) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO wager_escrows (escrow_id, match_id, player_a, player_b, stake, status)
+		 VALUES ($1, $2, $3, $4, $5, 'pending')`,
+		e.EscrowID, e.MatchID, e.PlayerA, e.PlayerB, e.Stake,
+	)
+	return err
+}
+
+// UpdateEscrowStatus moves an escrow from one status to another. Returns
+// false if it was no longer in the expected status, so each transition
+// happens at most once.
+func (r *Repository) UpdateEscrowStatus(ctx context.Context, escrowID, from, to, winnerID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE wager_escrows
+		 SET status = $3, winner_id = NULLIF($4, ''),
+		     settled_at = CASE WHEN $3 IN ('paid', 'refunded', 'cancelled') THEN NOW() ELSE settled_at END
+		 WHERE escrow_id = $1 AND status = $2`,
+		escrowID, from, to, winnerID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ListOpenEscrows returns every pending or held escrow.
+func (r *Repository) ListOpenEscrows(ctx context.Context) ([]*WagerEscrow, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT escrow_id, match_id, player_a, player_b, stake, status, winner_id
+		 FROM wager_escrows WHERE status IN ('pending', 'held')
+		 ORDER BY created_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	escrows := []*WagerEscrow{}
+	for rows.Next() {
+		var e WagerEscrow
+		var winnerID sql.NullString
+		if err := rows.Scan(&e.EscrowID, &e.MatchID, &e.PlayerA, &e.PlayerB, &e.Stake, &e.Status, &winnerID); err != nil {
+			return nil, err
+		}
+		e.WinnerID = winnerID.String
+		escrows = append(escrows, &e)
+	}
+	return escrows, rows.Err()
+}
+
+// ClaimDailyLogin records the player's login reward for the given day.
+// Returns false if it was already claimed.
+func (r *Repository) ClaimDailyLogin(ctx context.Context, userID string, day time.Time, coins int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO daily_login_rewards (user_id, reward_day, coins)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, reward_day) DO NOTHING`,
+		userID, day.Format("2006-01-02"), coins,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ReleaseDailyLogin removes a claim whose coins could not be credited, so
+// the player can try again.
+func (r *Repository) ReleaseDailyLogin(ctx context.Context, userID string, day time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM daily_login_rewards WHERE user_id = $1 AND reward_day = $2`,
+		userID, day.Format("2006-01-02"),
+	)
+	return err
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/heroiclabs/nakama-common/runtime"
 	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
 )
 
 // InitModule is the Nakama plugin entry point. It sets up the database
@@ -16,6 +17,11 @@ func InitModule(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runti
 		return err
 	}
 
+	// Matches do not survive a restart; refund any wagers they left in escrow.
+	if err := match.RecoverWagerEscrows(ctx, logger, db, nk); err != nil {
+		logger.Error("Wager escrow recovery failed: %v", err)
+	}
+
 	if err := RegisterRoutes(ctx, logger, db, nk, initializer); err != nil {
 		logger.Error("Route registration failed: %v", err)
 		return err
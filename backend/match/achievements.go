@@ -0,0 +1,188 @@
+package match
+
+import (
+	"context"
+	"time"
+
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+// Achievement condition kinds.
+const (
+	// AchievementWins unlocks at Threshold lifetime wins.
+	AchievementWins = "wins"
+	// AchievementWinInMoves unlocks on a win with at most Threshold marks placed.
+	AchievementWinInMoves = "win_in_moves"
+	// AchievementStreak unlocks at a current win streak of Threshold.
+	AchievementStreak = "streak"
+	// AchievementClutchWin unlocks on a win with under Threshold seconds left on the turn clock.
+	AchievementClutchWin = "clutch_win"
+	// AchievementBeatBot unlocks on a win against a bot of BotDifficulty.
+	AchievementBeatBot = "beat_bot"
+)
+
+// AchievementDef describes one unlockable achievement. Achievements are
+// data: add a definition here and checkAchievements picks it up.
+type AchievementDef struct {
+	ID            string
+	Title         string
+	Description   string
+	Kind          string
+	Threshold     int
+	Mode          string
+	BotDifficulty string
+}
+
+// Achievements is the full catalogue, in display order.
+var Achievements = []AchievementDef{
+	{ID: "first_win", Title: "First Victory", Description: "Win your first game.", Kind: AchievementWins, Threshold: 1},
+	{ID: "wins_10", Title: "Seasoned", Description: "Win 10 games.", Kind: AchievementWins, Threshold: 10},
+	{ID: "wins_100", Title: "Veteran", Description: "Win 100 games.", Kind: AchievementWins, Threshold: 100},
+	{ID: "win_in_3", Title: "Lightning Strike", Description: "Win a game in 3 moves.", Kind: AchievementWinInMoves, Threshold: 3},
+	{ID: "streak_10", Title: "Unstoppable", Description: "Win 10 games in a row.", Kind: AchievementStreak, Threshold: 10},
+	{ID: "clutch_timed", Title: "Clutch", Description: "Win a timed game with less than 2 seconds left.", Kind: AchievementClutchWin, Threshold: 2, Mode: ModeTimed},
+	{ID: "beat_hard_bot", Title: "Machine Breaker", Description: "Beat the hard bot.", Kind: AchievementBeatBot, BotDifficulty: "hard"},
+}
+
+// GameEndEvent summarises a finished game from one player's point of view.
+type GameEndEvent struct {
+	UserID    string
+	Mode      string
+	Outcome   string
+	Forfeit   bool
+	MovesMade int
+	Streak    int
+	TotalWins int
+	Rating    int
+	// OpponentRating is the opponent's rating after this game.
+	OpponentRating int
+	// TimeLeftSecs is what remained on the final turn clock, or -1 for
+	// untimed games.
+	TimeLeftSecs int
+	// OpponentBot is the opponent's bot difficulty, empty for humans and
+	// for seats the engine took over.
+	OpponentBot string
+}
+
+// unlockedBy reports whether the event satisfies the achievement.
+func (d AchievementDef) unlockedBy(e GameEndEvent) bool {
+	if d.Mode != "" && d.Mode != e.Mode {
+		return false
+	}
+
+	won := e.Outcome == dbpkg.OutcomeWin
+	switch d.Kind {
+	case AchievementWins:
+		return e.TotalWins >= d.Threshold
+	case AchievementWinInMoves:
+		return won && !e.Forfeit && e.MovesMade <= d.Threshold
+	case AchievementStreak:
+		return e.Streak >= d.Threshold
+	case AchievementClutchWin:
+		return won && !e.Forfeit && e.TimeLeftSecs >= 0 && e.TimeLeftSecs < d.Threshold
+	case AchievementBeatBot:
+		return won && e.OpponentBot == d.BotDifficulty
+	default:
+		return false
+	}
+}
+
+// gameEndEvents builds one event per human player of a finished game.
+// Stats must already have been updated so totals, streaks and ratings
+// include this game.
+func gameEndEvents(state *MatchState) []GameEndEvent {
+	timeLeft := -1
+	if state.Mode == ModeTimed && state.TurnStartTime > 0 {
+		timeLeft = int(state.TurnStartTime + int64(state.TurnTimeoutSecs) - time.Now().Unix())
+	}
+
+	events := make([]GameEndEvent, 0, len(state.Players))
+	for userID, player := range state.Players {
+		if player.IsBot {
+			continue
+		}
+
+		// Vanishing games clear marks, so count the moves played instead.
+		moves := 0
+		for _, m := range state.Moves {
+			if m.UserID == userID {
+				moves++
+			}
+		}
+
+		// A seat the engine took over is still the leaver's, so beating it
+		// does not count as beating a bot.
+		opponentRating, opponentBot := 0, ""
+		for opponentID, opponent := range state.Players {
+			if opponentID != userID {
+				opponentRating = opponent.Rating
+				if !opponent.TakenOver {
+					opponentBot = opponent.BotDifficulty
+				}
+			}
+		}
+
+		events = append(events, GameEndEvent{
+			UserID:         userID,
+			Mode:           state.Mode,
+			Outcome:        playerOutcome(state, userID),
+			Forfeit:        state.Forfeit,
+			MovesMade:      moves,
+			Streak:         player.Streak,
+			TotalWins:      player.Wins,
+			Rating:         player.Rating,
+			OpponentRating: opponentRating,
+			TimeLeftSecs:   timeLeft,
+			OpponentBot:    opponentBot,
+		})
+	}
+	return events
+}
+
+// checkAchievements unlocks any achievements earned in the finished game
+// and notifies the players about new ones.
+func (s *GameService) checkAchievements(ctx context.Context, events []GameEndEvent) {
+	repo := dbpkg.NewRepository(s.db)
+
+	for _, event := range events {
+		var earned []string
+		for _, def := range Achievements {
+			if def.unlockedBy(event) {
+				earned = append(earned, def.ID)
+			}
+		}
+		if len(earned) == 0 {
+			continue
+		}
+
+		unlocked, err := repo.UnlockAchievements(ctx, event.UserID, earned)
+		if err != nil {
+			s.logger.Error("Achievement unlock failed for %s: %v", event.UserID, err)
+			continue
+		}
+
+		for _, id := range unlocked {
+			def, _ := FindAchievement(id)
+			s.logger.Info("Achievement unlocked: %s by %s", id, event.UserID)
+
+			content := map[string]interface{}{
+				"achievement_id": def.ID,
+				"title":          def.Title,
+				"description":    def.Description,
+			}
+			if err := s.nk.NotificationSend(ctx, event.UserID, "Achievement unlocked", content, NotificationAchievementUnlocked, "", true); err != nil {
+				s.logger.Warn("Achievement notification to %s failed: %v", event.UserID, err)
+			}
+		}
+	}
+}
+
+// FindAchievement looks up an achievement definition by ID.
+func FindAchievement(id string) (AchievementDef, bool) {
+	for _, def := range Achievements {
+		if def.ID == id {
+			return def, true
+		}
+	}
+	return AchievementDef{}, false
+}
@@ -0,0 +1,69 @@
+package match
+
+import (
+	"testing"
+
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+func TestAchievementUnlockedBy(t *testing.T) {
+	wins := AchievementDef{Kind: AchievementWins, Threshold: 10}
+	quick := AchievementDef{Kind: AchievementWinInMoves, Threshold: 3}
+	streak := AchievementDef{Kind: AchievementStreak, Threshold: 10}
+	clutch := AchievementDef{Kind: AchievementClutchWin, Threshold: 2, Mode: ModeTimed}
+	beatHard := AchievementDef{Kind: AchievementBeatBot, BotDifficulty: BotHard}
+
+	win := func(e GameEndEvent) GameEndEvent {
+		e.Outcome = dbpkg.OutcomeWin
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		def   AchievementDef
+		event GameEndEvent
+		want  bool
+	}{
+		{"wins below threshold", wins, win(GameEndEvent{TotalWins: 9}), false},
+		{"wins at threshold", wins, win(GameEndEvent{TotalWins: 10}), true},
+		{"wins counted on a loss", wins, GameEndEvent{Outcome: dbpkg.OutcomeLoss, TotalWins: 12}, true},
+
+		{"quick win", quick, win(GameEndEvent{MovesMade: 3}), true},
+		{"slow win", quick, win(GameEndEvent{MovesMade: 4}), false},
+		{"quick forfeit win", quick, win(GameEndEvent{MovesMade: 1, Forfeit: true}), false},
+		{"quick loss", quick, GameEndEvent{Outcome: dbpkg.OutcomeLoss, MovesMade: 3}, false},
+
+		{"streak reached", streak, win(GameEndEvent{Streak: 10}), true},
+		{"streak short", streak, win(GameEndEvent{Streak: 9}), false},
+
+		{"clutch win", clutch, win(GameEndEvent{Mode: ModeTimed, TimeLeftSecs: 1}), true},
+		{"clutch with time to spare", clutch, win(GameEndEvent{Mode: ModeTimed, TimeLeftSecs: 2}), false},
+		{"clutch in another mode", clutch, win(GameEndEvent{Mode: ModeClassic, TimeLeftSecs: 1}), false},
+		{"clutch untimed clock", clutch, win(GameEndEvent{Mode: ModeTimed, TimeLeftSecs: -1}), false},
+		{"clutch forfeit", clutch, win(GameEndEvent{Mode: ModeTimed, TimeLeftSecs: 0, Forfeit: true}), false},
+
+		{"beat hard bot", beatHard, win(GameEndEvent{OpponentBot: BotHard}), true},
+		{"beat easy bot", beatHard, win(GameEndEvent{OpponentBot: BotEasy}), false},
+		{"beat human", beatHard, win(GameEndEvent{}), false},
+		{"lost to hard bot", beatHard, GameEndEvent{Outcome: dbpkg.OutcomeLoss, OpponentBot: BotHard}, false},
+
+		{"unknown kind", AchievementDef{Kind: "nope"}, win(GameEndEvent{TotalWins: 100}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.def.unlockedBy(tt.event); got != tt.want {
+				t.Errorf("unlockedBy(%+v) = %v, want %v", tt.event, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAchievementCatalogueIDsAreUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, def := range Achievements {
+		if seen[def.ID] {
+			t.Errorf("duplicate achievement ID %q", def.ID)
+		}
+		seen[def.ID] = true
+	}
+}
@@ -0,0 +1,211 @@
+package match
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
+)
+
+// ArenaLeaderboardID returns the leaderboard that holds an arena's points.
+func ArenaLeaderboardID(arenaID string) string {
+	return LeaderboardArenaPrefix + arenaID
+}
+
+// PairArenaPlayers matches up waiting arena players of similar rating and
+// starts a match for each pair. Both players are told which match to join
+// via notification. Safe to call concurrently — each pair is claimed
+// atomically before its match is created.
+func PairArenaPlayers(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, arenaID string) {
+	repo := dbpkg.NewRepository(db)
+
+	arena, err := repo.GetArena(ctx, arenaID)
+	if err != nil {
+		logger.Error("Arena lookup failed for %s: %v", arenaID, err)
+		return
+	}
+	now := time.Now()
+	if !arena.IsRunning(now) {
+		return
+	}
+
+	waiting, err := repo.ListWaitingArenaPlayers(ctx, arenaID)
+	if err != nil {
+		logger.Error("Arena pool fetch failed for %s: %v", arenaID, err)
+		return
+	}
+
+	blocks, err := repo.BlocksAmong(ctx, arenaPlayerIDs(waiting))
+	if err != nil {
+		logger.Error("Arena block lookup failed for %s: %v", arenaID, err)
+		return
+	}
+
+	for _, pair := range pairByRating(waiting, now, blocks) {
+		a, b := pair[0], pair[1]
+
+		claimed, err := repo.ClaimArenaPair(ctx, arenaID, a.UserID, b.UserID)
+		if err != nil {
+			logger.Error("Arena claim failed for %s/%s: %v", a.UserID, b.UserID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		matchID, err := nk.MatchCreate(ctx, "tictactoe", map[string]interface{}{
+			"mode":         arena.Mode,
+			"arena_id":     arenaID,
+			"reserved_for": strings.Join([]string{a.UserID, b.UserID}, ","),
+		})
+		if err != nil {
+			logger.Error("Arena match creation failed: %v", err)
+			if err := repo.ReleaseArenaPlayers(ctx, arenaID, a.UserID, b.UserID); err != nil {
+				logger.Error("Arena release failed: %v", err)
+			}
+			continue
+		}
+
+		if err := repo.SetArenaMatch(ctx, arenaID, matchID, a.UserID, b.UserID); err != nil {
+			logger.Error("Arena match bookkeeping failed: %v", err)
+		}
+
+		logger.Info("Arena %s paired %s (%d) vs %s (%d) in %s", arenaID, a.Username, a.Rating, b.Username, b.Rating, matchID)
+		notifyArenaPairing(ctx, logger, nk, arenaID, matchID, a, b)
+		notifyArenaPairing(ctx, logger, nk, arenaID, matchID, b, a)
+	}
+}
+
+// pairByRating greedily pairs neighbours in rating order. The allowed gap
+// widens the longer either player has been waiting, so outliers are
+// eventually paired rather than sitting out the whole arena. Players who
+// have blocked each other are never paired.
+func pairByRating(players []*dbpkg.ArenaPlayer, now time.Time, blocks dbpkg.BlockSet) [][2]*dbpkg.ArenaPlayer {
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Rating < players[j].Rating
+	})
+
+	var pairs [][2]*dbpkg.ArenaPlayer
+	for i := 0; i+1 < len(players); {
+		a, b := players[i], players[i+1]
+		if !blocks.Either(a.UserID, b.UserID) && utils.Abs(a.Rating-b.Rating) <= arenaRatingGap(a, b, now) {
+			pairs = append(pairs, [2]*dbpkg.ArenaPlayer{a, b})
+			i += 2
+		} else {
+			i++
+		}
+	}
+	return pairs
+}
+
+func arenaPlayerIDs(players []*dbpkg.ArenaPlayer) []string {
+	ids := make([]string, len(players))
+	for i, p := range players {
+		ids[i] = p.UserID
+	}
+	return ids
+}
+
+func arenaRatingGap(a, b *dbpkg.ArenaPlayer, now time.Time) int {
+	since := a.WaitingSince
+	if b.WaitingSince.Before(since) {
+		since = b.WaitingSince
+	}
+	waited := max(int(now.Sub(since).Seconds()), 0)
+	return ArenaBaseRatingGap + waited*ArenaRatingGapPerSec
+}
+
+// arenaPoints scores one result given the player's win streak going into
+// the game, and returns the new streak.
+func arenaPoints(won, isDraw bool, streak int) (points, newStreak int) {
+	multiplier := 1
+	if streak >= ArenaFireStreak {
+		multiplier = 2
+	}
+
+	switch {
+	case won:
+		return ArenaWinPoints * multiplier, streak + 1
+	case isDraw:
+		return ArenaDrawPoints * multiplier, 0
+	default:
+		return 0, 0
+	}
+}
+
+// recordArenaResult awards arena points for a finished game, updates the
+// arena leaderboard, and puts still-connected players back into the pool.
+func (s *GameService) recordArenaResult(ctx context.Context, state *MatchState) {
+	if state.ArenaID == "" {
+		return
+	}
+
+	repo := dbpkg.NewRepository(s.db)
+	for userID, player := range state.Players {
+		standing, err := repo.GetArenaPlayer(ctx, state.ArenaID, userID)
+		if err != nil {
+			s.logger.Error("Arena standing fetch failed for %s: %v", userID, err)
+			continue
+		}
+
+		points, streak := arenaPoints(userID == state.Winner, state.IsDraw, standing.Streak)
+		status := dbpkg.ArenaWaiting
+		if !player.IsConnected {
+			status = dbpkg.ArenaIdle
+		}
+
+		updated, err := repo.RecordArenaGame(ctx, state.ArenaID, userID, points, streak, status)
+		if err != nil {
+			s.logger.Error("Arena result write failed for %s: %v", userID, err)
+			continue
+		}
+
+		if _, err := s.nk.LeaderboardRecordWrite(context.Background(), ArenaLeaderboardID(state.ArenaID), userID, player.Username, int64(updated.Points), int64(updated.GamesPlayed), nil, nil); err != nil {
+			s.logger.Error("Arena leaderboard write failed for %s: %v", userID, err)
+		}
+	}
+
+	PairArenaPlayers(ctx, s.logger, s.db, s.nk, state.ArenaID)
+}
+
+// releaseArenaSeats hands the players of an arena match that ended without
+// a result back to the arena. Those in idle sit out until they join again;
+// the rest go back into the pool.
+func (s *GameService) releaseArenaSeats(ctx context.Context, state *MatchState, idle []string) {
+	if state.ArenaID == "" {
+		return
+	}
+
+	repo := dbpkg.NewRepository(s.db)
+	for _, userID := range state.ReservedFor {
+		var err error
+		if utils.ContainsString(idle, userID) {
+			err = repo.ResetArenaPlayer(ctx, state.ArenaID, userID, state.MatchID)
+		} else {
+			err = repo.ReleaseArenaPlayers(ctx, state.ArenaID, userID)
+		}
+		if err != nil {
+			s.logger.Error("Arena release failed for %s: %v", userID, err)
+		}
+	}
+
+	PairArenaPlayers(ctx, s.logger, s.db, s.nk, state.ArenaID)
+}
+
+func notifyArenaPairing(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, arenaID, matchID string, player, opponent *dbpkg.ArenaPlayer) {
+	content := map[string]interface{}{
+		"arena_id":        arenaID,
+		"match_id":        matchID,
+		"opponent_id":     opponent.UserID,
+		"opponent":        opponent.Username,
+		"opponent_rating": opponent.Rating,
+	}
+	if err := nk.NotificationSend(ctx, player.UserID, "Arena opponent found", content, NotificationArenaPaired, "", false); err != nil {
+		logger.Warn("Arena notification to %s failed: %v", player.UserID, err)
+	}
+}
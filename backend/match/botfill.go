@@ -0,0 +1,123 @@
+package match
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// envBotFillWaitSecs is the runtime env key (Nakama's runtime.env config)
+// for how long a find_match player waits alone before being offered a bot.
+// "0" turns bot-fill off.
+const envBotFillWaitSecs = "bot_fill_wait_secs"
+
+// Bot-fill picks the bot difficulty from the waiting player's rating.
+const (
+	DefaultBotFillWaitSecs = 30
+	BotFillMediumRating    = 950
+	BotFillHardRating      = 1100
+)
+
+// BotOffer is sent with OpCodeBotOffer to a player who has waited
+// BotFillSecs for an opponent.
+type BotOffer struct {
+	Difficulty string `json:"difficulty"`
+	WaitedSecs int64  `json:"waited_secs"`
+}
+
+// BotOfferReply is the player's answer to a BotOffer.
+type BotOfferReply struct {
+	Accept bool `json:"accept"`
+}
+
+// BotFillWaitSecs returns the configured bot-fill wait.
+func BotFillWaitSecs(ctx context.Context) int {
+	env, _ := ctx.Value(runtime.RUNTIME_CTX_ENV).(map[string]string)
+	if configured, ok := env[envBotFillWaitSecs]; ok {
+		if secs, err := strconv.Atoi(configured); err == nil && secs >= 0 {
+			return secs
+		}
+	}
+	return DefaultBotFillWaitSecs
+}
+
+// BotDifficultyForRating matches a bot to a player's rating.
+func BotDifficultyForRating(rating int) string {
+	switch {
+	case rating >= BotFillHardRating:
+		return BotHard
+	case rating >= BotFillMediumRating:
+		return BotMedium
+	default:
+		return BotEasy
+	}
+}
+
+// waitingPlayer returns the lone player of a bot-fill match who has waited
+// long enough to be offered a bot.
+func (ms *MatchState) waitingPlayer(now int64) (*PlayerData, bool) {
+	if ms.BotFillSecs == 0 || ms.BotOffered || ms.BotDifficulty != "" || len(ms.Players) != 1 || ms.waitingSince == 0 {
+		return nil, false
+	}
+	if now-ms.waitingSince < int64(ms.BotFillSecs) {
+		return nil, false
+	}
+	for _, p := range ms.Players {
+		if p.IsConnected && !p.IsBot {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// offerBot tells a player who has waited too long that a bot can take the
+// empty seat. The offer is made once; a human may still join meanwhile.
+func (s *GameService) offerBot(state *MatchState) {
+	player, ok := state.waitingPlayer(time.Now().Unix())
+	if !ok {
+		return
+	}
+	presence, ok := state.presences[player.UserID]
+	if !ok {
+		return
+	}
+
+	state.BotOffered = true
+	payload, _ := json.Marshal(BotOffer{
+		Difficulty: BotDifficultyForRating(player.Rating),
+		WaitedSecs: time.Now().Unix() - state.waitingSince,
+	})
+	s.dispatcher.BroadcastMessage(OpCodeBotOffer, payload, []runtime.Presence{presence}, nil, true)
+	s.logger.Info("Offered a bot to %s in %s", player.Username, state.MatchID)
+}
+
+// HandleBotOfferReply seats a bot matched to the player's rating if they
+// accept. The game becomes an unrated bot game.
+func (s *GameService) HandleBotOfferReply(ctx context.Context, state *MatchState, userID string, reply BotOfferReply) error {
+	player, ok := state.Players[userID]
+	switch {
+	case !ok:
+		return fmt.Errorf("player not in match")
+	case !state.BotOffered:
+		return fmt.Errorf("no bot was offered")
+	case len(state.Players) != 1:
+		return fmt.Errorf("an opponent has already joined")
+	}
+	if !reply.Accept {
+		s.logger.Info("%s declined a bot in %s", player.Username, state.MatchID)
+		return nil
+	}
+
+	state.BotDifficulty = BotDifficultyForRating(player.Rating)
+	state.Casual = true
+	s.addBot(state, SymbolO)
+	if err := s.dispatcher.MatchLabelUpdate(matchLabel(state)); err != nil {
+		s.logger.Warn("Label update failed in %s: %v", state.MatchID, err)
+	}
+	s.startGame(ctx, state)
+	return nil
+}
@@ -0,0 +1,131 @@
+package match
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/prasanth-33460/tic-tac-toe/backend/solver"
+)
+
+// Bot difficulties. The learning bot plays from weights it trains on its
+// own games; the others play from the solver.
+const (
+	BotEasy     = "easy"
+	BotMedium   = "medium"
+	BotHard     = "hard"
+	BotLearning = "learning"
+
+	// botUserPrefix marks the user ID of a server-side bot seat. Bots have
+	// no Nakama account.
+	botUserPrefix = "bot_"
+
+	// botMediumBestChance is how often the medium bot plays a best move
+	// rather than any legal one.
+	botMediumBestChance = 0.7
+)
+
+// BotDifficulties lists every difficulty in display order.
+var BotDifficulties = []string{BotEasy, BotMedium, BotHard, BotLearning}
+
+// IsBotDifficulty reports whether d names a bot difficulty.
+func IsBotDifficulty(d string) bool {
+	for _, difficulty := range BotDifficulties {
+		if d == difficulty {
+			return true
+		}
+	}
+	return false
+}
+
+// BotUserID returns the user ID of the bot seat for difficulty.
+func BotUserID(difficulty string) string {
+	return botUserPrefix + difficulty
+}
+
+// EngineBot reports whether the seat is played by the server itself.
+func (p *PlayerData) EngineBot() bool {
+	return p.BotDifficulty != ""
+}
+
+// addBot seats the match's bot opposite the human who just joined.
+func (s *GameService) addBot(state *MatchState, symbol string) *PlayerData {
+	bot := &PlayerData{
+		UserID:        BotUserID(state.BotDifficulty),
+		Username:      fmt.Sprintf("Bot (%s)", state.BotDifficulty),
+		Symbol:        symbol,
+		IsConnected:   true,
+		IsBot:         true,
+		BotDifficulty: state.BotDifficulty,
+	}
+	state.Players[bot.UserID] = bot
+	s.logger.Info("Bot joined: %s as %s", bot.Username, symbol)
+	return bot
+}
+
+// botToMove returns the engine bot whose turn it is, if any.
+func (ms *MatchState) botToMove() (*PlayerData, bool) {
+	if ms.GameOver || len(ms.Players) < MaxPlayers {
+		return nil, false
+	}
+	player, ok := ms.Players[ms.CurrentTurnID]
+	if !ok || !player.EngineBot() {
+		return nil, false
+	}
+	return player, true
+}
+
+// playBotMove makes the engine bot's move when it is on turn. It runs once
+// per tick, so the bot answers about a second after the human moves.
+func (s *GameService) playBotMove(ctx context.Context, state *MatchState, tick int64) {
+	bot, ok := state.botToMove()
+	if !ok {
+		return
+	}
+
+	position, err := s.chooseBotMove(ctx, state, bot)
+	if err != nil {
+		s.logger.Error("Bot move failed in %s: %v", state.MatchID, err)
+		position = findFirstEmptyCell(state)
+	}
+	if err := s.ProcessMove(ctx, state, bot.UserID, position, tick); err != nil {
+		s.logger.Error("Bot move rejected in %s: %v", state.MatchID, err)
+	}
+}
+
+func (s *GameService) chooseBotMove(ctx context.Context, state *MatchState, bot *PlayerData) (int, error) {
+	if bot.BotDifficulty == BotLearning {
+		return s.chooseLearningMove(ctx, state, bot)
+	}
+
+	legal := emptyCells(state)
+	if len(legal) == 0 {
+		return -1, fmt.Errorf("no moves available")
+	}
+	if bot.BotDifficulty == BotEasy || (bot.BotDifficulty == BotMedium && rand.Float64() >= botMediumBestChance) {
+		return legal[rand.Intn(len(legal))], nil
+	}
+
+	board, err := state.solverBoard()
+	if err != nil {
+		return -1, err
+	}
+	analysis, err := solver.Analyze(board, bot.Symbol, 0)
+	if err != nil {
+		return -1, err
+	}
+	if len(analysis.BestMoves) == 0 {
+		return -1, fmt.Errorf("no moves available")
+	}
+	return analysis.BestMoves[rand.Intn(len(analysis.BestMoves))], nil
+}
+
+func emptyCells(state *MatchState) []int {
+	var cells []int
+	for i, cell := range state.Board {
+		if cell == "" {
+			cells = append(cells, i)
+		}
+	}
+	return cells
+}
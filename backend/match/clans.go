@@ -0,0 +1,234 @@
+package match
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
+)
+
+// ClanMetadataKey marks a Nakama group as a clan in its metadata, so other
+// groups a player belongs to are ignored.
+const ClanMetadataKey = "clan"
+
+// clanGroupScanLimit caps how many of a player's groups are checked when
+// looking for their clan.
+const clanGroupScanLimit = 100
+
+// IsClan reports whether the group was created as a clan.
+func IsClan(group *api.Group) bool {
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(group.GetMetadata()), &metadata); err != nil {
+		return false
+	}
+	isClan, _ := metadata[ClanMetadataKey].(bool)
+	return isClan
+}
+
+// ClanForUser returns the clan the player is a member of, or nil if they
+// are not in one. Pending join requests do not count.
+func ClanForUser(ctx context.Context, nk runtime.NakamaModule, userID string) (*api.Group, error) {
+	groups, _, err := nk.UserGroupsList(ctx, userID, clanGroupScanLimit, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.GetState().GetValue() <= int32(api.UserGroupList_UserGroup_MEMBER) && IsClan(g.GetGroup()) {
+			return g.GetGroup(), nil
+		}
+	}
+	return nil, nil
+}
+
+// writeClanWin adds a win to the winner's clan on the clan leaderboard.
+func (s *GameService) writeClanWin(ctx context.Context, userID string) {
+	clan, err := ClanForUser(ctx, s.nk, userID)
+	if err != nil {
+		s.logger.Warn("Clan lookup failed for %s: %v", userID, err)
+		return
+	}
+	if clan == nil {
+		return
+	}
+
+	if _, err := s.nk.LeaderboardRecordWrite(context.Background(), LeaderboardClanWins, clan.GetId(), clan.GetName(), 1, 0, nil, nil); err != nil {
+		s.logger.Error("Clan leaderboard write failed for %s: %v", clan.GetId(), err)
+	}
+}
+
+// PairClanWarPlayers pairs waiting members of one clan with waiting members
+// of the other and starts a match for each pair. Like arena pairing, each
+// pair is claimed atomically so concurrent calls are safe.
+func PairClanWarPlayers(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, warID string) {
+	repo := dbpkg.NewRepository(db)
+
+	war, err := repo.GetClanWar(ctx, warID)
+	if err != nil {
+		logger.Error("Clan war lookup failed for %s: %v", warID, err)
+		return
+	}
+	if !war.IsRunning(time.Now()) {
+		return
+	}
+
+	waiting, err := repo.ListWaitingClanWarPlayers(ctx, warID)
+	if err != nil {
+		logger.Error("Clan war pool fetch failed for %s: %v", warID, err)
+		return
+	}
+
+	ids := make([]string, len(waiting))
+	for i, p := range waiting {
+		ids[i] = p.UserID
+	}
+	blocks, err := repo.BlocksAmong(ctx, ids)
+	if err != nil {
+		logger.Error("Clan war block lookup failed for %s: %v", warID, err)
+		return
+	}
+
+	for _, pair := range pairAcrossClans(waiting, war.ClanA, blocks) {
+		a, b := pair[0], pair[1]
+
+		claimed, err := repo.ClaimClanWarPair(ctx, warID, a.UserID, b.UserID)
+		if err != nil {
+			logger.Error("Clan war claim failed for %s/%s: %v", a.UserID, b.UserID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		matchID, err := nk.MatchCreate(ctx, "tictactoe", map[string]interface{}{
+			"mode":         war.Mode,
+			"clan_war_id":  warID,
+			"reserved_for": strings.Join([]string{a.UserID, b.UserID}, ","),
+		})
+		if err != nil {
+			logger.Error("Clan war match creation failed: %v", err)
+			if err := repo.ReleaseClanWarPlayers(ctx, warID, a.UserID, b.UserID); err != nil {
+				logger.Error("Clan war release failed: %v", err)
+			}
+			continue
+		}
+
+		if err := repo.SetClanWarMatch(ctx, warID, matchID, a.UserID, b.UserID); err != nil {
+			logger.Error("Clan war match bookkeeping failed: %v", err)
+		}
+
+		logger.Info("Clan war %s paired %s vs %s in %s", warID, a.Username, b.Username, matchID)
+		notifyClanWarPairing(ctx, logger, nk, warID, matchID, a, b)
+		notifyClanWarPairing(ctx, logger, nk, warID, matchID, b, a)
+	}
+}
+
+// pairAcrossClans pairs each waiting member of clanA, longest waiting
+// first, with the closest-rated waiting member of the other clan they have
+// no block with.
+func pairAcrossClans(players []*dbpkg.ClanWarPlayer, clanA string, blocks dbpkg.BlockSet) [][2]*dbpkg.ClanWarPlayer {
+	var sideA, sideB []*dbpkg.ClanWarPlayer
+	for _, p := range players {
+		if p.ClanID == clanA {
+			sideA = append(sideA, p)
+		} else {
+			sideB = append(sideB, p)
+		}
+	}
+	sort.SliceStable(sideA, func(i, j int) bool {
+		return sideA[i].WaitingSince.Before(sideA[j].WaitingSince)
+	})
+
+	var pairs [][2]*dbpkg.ClanWarPlayer
+	for _, a := range sideA {
+		best := -1
+		for i, b := range sideB {
+			if blocks.Either(a.UserID, b.UserID) {
+				continue
+			}
+			if best < 0 || utils.Abs(a.Rating-b.Rating) < utils.Abs(a.Rating-sideB[best].Rating) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		pairs = append(pairs, [2]*dbpkg.ClanWarPlayer{a, sideB[best]})
+		sideB = append(sideB[:best], sideB[best+1:]...)
+	}
+	return pairs
+}
+
+// recordClanWarResult adds the game's points to both members' clans and
+// puts still-connected members back into the pool.
+func (s *GameService) recordClanWarResult(ctx context.Context, state *MatchState) {
+	if state.ClanWarID == "" {
+		return
+	}
+
+	repo := dbpkg.NewRepository(s.db)
+	for userID, player := range state.Players {
+		points := 0
+		switch {
+		case state.Winner == userID:
+			points = ClanWarWinPoints
+		case state.IsDraw:
+			points = ClanWarDrawPoints
+		}
+
+		status := dbpkg.ArenaWaiting
+		if !player.IsConnected {
+			status = dbpkg.ArenaIdle
+		}
+
+		if err := repo.RecordClanWarGame(ctx, state.ClanWarID, userID, points, status); err != nil {
+			s.logger.Error("Clan war result write failed for %s: %v", userID, err)
+		}
+	}
+
+	PairClanWarPlayers(ctx, s.logger, s.db, s.nk, state.ClanWarID)
+}
+
+// releaseClanWarSeats hands the members of a clan war match that ended
+// without a result back to the war. Those in idle sit out until they join
+// again; the rest go back into the pool.
+func (s *GameService) releaseClanWarSeats(ctx context.Context, state *MatchState, idle []string) {
+	if state.ClanWarID == "" {
+		return
+	}
+
+	repo := dbpkg.NewRepository(s.db)
+	for _, userID := range state.ReservedFor {
+		var err error
+		if utils.ContainsString(idle, userID) {
+			err = repo.ResetClanWarPlayer(ctx, state.ClanWarID, userID, state.MatchID)
+		} else {
+			err = repo.ReleaseClanWarPlayers(ctx, state.ClanWarID, userID)
+		}
+		if err != nil {
+			s.logger.Error("Clan war release failed for %s: %v", userID, err)
+		}
+	}
+
+	PairClanWarPlayers(ctx, s.logger, s.db, s.nk, state.ClanWarID)
+}
+
+func notifyClanWarPairing(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, warID, matchID string, player, opponent *dbpkg.ClanWarPlayer) {
+	content := map[string]interface{}{
+		"war_id":          warID,
+		"match_id":        matchID,
+		"opponent_id":     opponent.UserID,
+		"opponent":        opponent.Username,
+		"opponent_rating": opponent.Rating,
+		"opponent_clan":   opponent.ClanID,
+	}
+	if err := nk.NotificationSend(ctx, player.UserID, "Clan war opponent found", content, NotificationClanWarPaired, "", false); err != nil {
+		logger.Warn("Clan war notification to %s failed: %v", player.UserID, err)
+	}
+}
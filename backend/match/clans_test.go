@@ -0,0 +1,95 @@
+package match
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+func TestPairAcrossClans(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	player := func(userID, clanID string, rating int, waitedSecs int) *dbpkg.ClanWarPlayer {
+		return &dbpkg.ClanWarPlayer{
+			UserID:       userID,
+			ClanID:       clanID,
+			Rating:       rating,
+			WaitingSince: start.Add(-time.Duration(waitedSecs) * time.Second),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		players []*dbpkg.ClanWarPlayer
+		blocks  dbpkg.BlockSet
+		want    []string
+	}{
+		{
+			name: "closest rating",
+			players: []*dbpkg.ClanWarPlayer{
+				player("a1", "A", 1000, 10),
+				player("b1", "B", 1500, 10),
+				player("b2", "B", 1010, 10),
+			},
+			want: []string{"a1-b2"},
+		},
+		{
+			name: "longest waiting chooses first",
+			players: []*dbpkg.ClanWarPlayer{
+				player("a1", "A", 1000, 5),
+				player("a2", "A", 1000, 60),
+				player("b1", "B", 1000, 10),
+				player("b2", "B", 1400, 10),
+			},
+			want: []string{"a2-b1", "a1-b2"},
+		},
+		{
+			name: "blocked pair skipped",
+			players: []*dbpkg.ClanWarPlayer{
+				player("a1", "A", 1000, 10),
+				player("b1", "B", 1000, 10),
+				player("b2", "B", 1300, 10),
+			},
+			blocks: dbpkg.BlockSet{{"b1", "a1"}: true},
+			want:   []string{"a1-b2"},
+		},
+		{
+			name: "extra members wait",
+			players: []*dbpkg.ClanWarPlayer{
+				player("a1", "A", 1000, 30),
+				player("a2", "A", 1000, 20),
+				player("b1", "B", 1000, 10),
+			},
+			want: []string{"a1-b1"},
+		},
+		{
+			name: "one clan only",
+			players: []*dbpkg.ClanWarPlayer{
+				player("a1", "A", 1000, 10),
+				player("a2", "A", 1000, 10),
+			},
+			want: nil,
+		},
+		{
+			name: "everyone blocked",
+			players: []*dbpkg.ClanWarPlayer{
+				player("a1", "A", 1000, 10),
+				player("b1", "B", 1000, 10),
+			},
+			blocks: dbpkg.BlockSet{{"a1", "b1"}: true},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, pair := range pairAcrossClans(tt.players, "A", tt.blocks) {
+				got = append(got, pair[0].UserID+"-"+pair[1].UserID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("pairs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
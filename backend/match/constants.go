@@ -1,16 +1,89 @@
 package match
 
+import dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+
 const (
 	TickRate   = 1
 	MaxPlayers = 2
 	BoardSize  = 9
 
-	ModeClassic = "classic"
-	ModeTimed   = "timed"
+	ModeClassic        = dbpkg.ModeClassic
+	ModeTimed          = dbpkg.ModeTimed
+	ModeCorrespondence = dbpkg.ModeCorrespondence
+
+	// ModeMisere plays the classic board under misère rules: completing
+	// three in a row loses.
+	ModeMisere = dbpkg.ModeMisere
+
+	// ModeVanishing limits each player to VanishingMaxPieces marks; a new
+	// mark removes their oldest. Games are drawn on the VanishingRepetitions
+	// time a position repeats or after VanishingMoveCap moves.
+	ModeVanishing        = dbpkg.ModeVanishing
+	VanishingMaxPieces   = 3
+	VanishingRepetitions = 3
+	VanishingMoveCap     = 60
 
 	// TurnTimeoutSecs is the per-turn time limit in timed mode.
 	TurnTimeoutSecs = 15
 
+	// Correspondence games give each player hours or days per move.
+	DefaultCorrespondenceMoveHours = 24
+	MinCorrespondenceMoveHours     = 1
+	MaxCorrespondenceMoveHours     = 7 * 24
+
+	// MaxActiveCorrespondenceGames caps how many unfinished correspondence
+	// games a single player can have at once.
+	MaxActiveCorrespondenceGames = 50
+
+	// Arena events pair players continuously for a fixed duration.
+	DefaultArenaMinutes = 30
+	MaxArenaMinutes     = 240
+
+	// ArenaBaseRatingGap is the widest rating gap allowed between two
+	// freshly queued arena players. It widens by ArenaRatingGapPerSec for
+	// every second the longer-waiting player has been in the pool.
+	ArenaBaseRatingGap   = 100
+	ArenaRatingGapPerSec = 10
+
+	// Arena points per result. Players who have won ArenaFireStreak games
+	// in a row score double until they fail to win.
+	ArenaWinPoints  = 2
+	ArenaDrawPoints = 1
+	ArenaFireStreak = 2
+
+	// PairedSeatTimeoutSecs is how long an arena or clan war match waits
+	// for both paired players to join before it is closed and they are released.
+	PairedSeatTimeoutSecs = 60
+
+	// Clans are Nakama groups; wars between two clans are scheduled ahead
+	// and run for a fixed duration.
+	MaxClanMembers         = 50
+	DefaultClanWarMinutes  = 60
+	MaxClanWarMinutes      = 24 * 60
+	MaxClanWarScheduleDays = 30
+	ClanWarWinPoints       = 2
+	ClanWarDrawPoints      = 1
+
+	// EloKFactor scales how far a single rated result moves a player's
+	// skill rating (player_stats.skill_rating, default 1000), which arena
+	// pairing matches on.
+	EloKFactor = 32
+
+	// SeasonArchiveLimit is how many top players of each seasonal board
+	// are archived (and rewarded) when the season resets.
+	SeasonArchiveLimit = 100
+
+	// WalletCurrency is the wallet key holding a player's coins.
+	WalletCurrency = "coins"
+
+	// Coins credited for a rated win over a human and for the first login
+	// of each UTC day.
+	CoinsPerWin     = 10
+	DailyLoginCoins = 25
+
+	// MaxWagerStake caps the stake of a wagered match.
+	MaxWagerStake = 10000
+
 	// SymbolX and SymbolO are the two player markers.
 	SymbolX = "X"
 	SymbolO = "O"
@@ -21,9 +94,45 @@ const (
 	// MaxChatLength is the maximum allowed characters in a chat message.
 	MaxChatLength = 500
 
-	// Leaderboard IDs used across the server.
-	LeaderboardGlobalWins = "global_wins"
-	LeaderboardWinStreaks = "win_streaks"
+	// A player may send at most ChatRateLimit messages in any
+	// ChatRateWindowSecs window.
+	ChatRateLimit      = 5
+	ChatRateWindowSecs = 10
+
+	// A player may send at most EmoteRateLimit emotes in any
+	// EmoteRateWindowSecs window.
+	EmoteRateLimit      = 3
+	EmoteRateWindowSecs = 5
+
+	// ReconnectWindowSecs is how long a casual game waits for a
+	// disconnected player before the engine takes over their seat.
+	ReconnectWindowSecs = 30
+
+	// External bots must move within BotMoveTimeoutSecs in every mode and
+	// may send at most BotMessageRateLimit messages in any
+	// BotMessageRateWindowSecs window.
+	BotMoveTimeoutSecs       = 5
+	BotMessageRateLimit      = 10
+	BotMessageRateWindowSecs = 5
+
+	// BotJoinMetadataKey is the join metadata flag a registered bot account
+	// sets to "true".
+	BotJoinMetadataKey = "bot"
+
+	// MaxHintsPerGame is how many hints each player may use in one casual
+	// game. Rated games allow none.
+	MaxHintsPerGame = 3
+
+	// Leaderboard IDs used across the server, as created by the db package.
+	LeaderboardGlobalWins = dbpkg.LeaderboardGlobalWins
+	LeaderboardWinStreaks = dbpkg.LeaderboardWinStreaks
+	LeaderboardClanWins   = dbpkg.LeaderboardClanWins
+	LeaderboardPuzzle     = dbpkg.LeaderboardPuzzle
+	LeaderboardBotLadder  = dbpkg.LeaderboardBotLadder
+
+	// LeaderboardArenaPrefix is prepended to an arena ID to form its
+	// leaderboard ID.
+	LeaderboardArenaPrefix = "arena_"
 
 	// Op-codes for real-time match messages.
 	OpCodeMove    int64 = 1
@@ -31,6 +140,31 @@ const (
 	OpCodeGameEnd int64 = 3
 	OpCodeTimeout int64 = 4
 	OpCodeChat    int64 = 5
+
+	// OpCodeChatError is sent only to the sender of a rejected chat message.
+	OpCodeChatError int64 = 6
+
+	// OpCodeEmote carries a quick-chat emote ID; it is allowed even when
+	// free-text chat is off.
+	OpCodeEmote int64 = 7
+
+	// OpCodeHint is a hint request from the player to move, answered to
+	// them alone with a suggested position or an error.
+	OpCodeHint int64 = 8
+
+	// OpCodeBotOffer offers a player who has waited too long a bot
+	// opponent; they answer on the same opcode.
+	OpCodeBotOffer int64 = 9
+
+	// Notification codes sent via nk.NotificationSend.
+	NotificationCorrespondenceInvite = 100
+	NotificationCorrespondenceTurn   = 101
+	NotificationCorrespondenceOver   = 102
+	NotificationArenaPaired          = 110
+	NotificationSeasonReward         = 120
+	NotificationAchievementUnlocked  = 130
+	NotificationLevelUp              = 140
+	NotificationClanWarPaired        = 150
 )
 
 // WinPatterns lists every set of three board indices that form a line.
@@ -0,0 +1,81 @@
+package match
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// NewCorrespondenceState builds the initial state of a correspondence game.
+// The challenger plays X and moves first; each player then has moveHours to
+// answer before losing on time.
+func NewCorrespondenceState(gameID string, challenger, opponent *PlayerData, moveHours int) *MatchState {
+	state := NewGameState(ModeCorrespondence)
+	state.MatchID = gameID
+	state.TurnTimeoutSecs = moveHours * 3600
+
+	challenger.Symbol = SymbolX
+	opponent.Symbol = SymbolO
+	state.Players[challenger.UserID] = challenger
+	state.Players[opponent.UserID] = opponent
+
+	now := time.Now().Unix()
+	state.CurrentTurnID = challenger.UserID
+	state.StartTime = now
+	state.TurnStartTime = now
+	return state
+}
+
+// PlayCorrespondenceMove applies a move using the same rules as a live
+// match. It only mutates state — once the new state has been saved, call
+// RecordCorrespondenceResult if the game is over.
+func PlayCorrespondenceMove(state *MatchState, userID string, position int) error {
+	if err := ValidateMove(state, userID, position); err != nil {
+		return err
+	}
+
+	player, exists := state.Players[userID]
+	if !exists {
+		return fmt.Errorf("player not in match: %s", userID)
+	}
+
+	winner, isDraw := placeMark(state, player, position)
+	if winner != "" || isDraw {
+		state.GameOver = true
+		state.Winner = winner
+		state.IsDraw = isDraw
+		return nil
+	}
+
+	state.SwitchTurn(0)
+	return nil
+}
+
+// RecordCorrespondenceResult records a finished correspondence game through
+// the same game-end path as a live match. Correspondence games have no
+// running match, so the service gets no dispatcher; nothing on the
+// game-end path broadcasts.
+func RecordCorrespondenceResult(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, state *MatchState) {
+	NewGameService(logger, db, nk, nil).RecordResult(ctx, state)
+}
+
+// ExpireCorrespondenceTurn ends the game in the opponent's favour if the
+// player to move has let their deadline pass. Returns true if the game was
+// ended by this call.
+func ExpireCorrespondenceTurn(state *MatchState) bool {
+	if state.Mode != ModeCorrespondence || state.GameOver || !state.IsTimedOut() {
+		return false
+	}
+
+	state.GameOver = true
+	state.Forfeit = true
+	for userID := range state.Players {
+		if userID != state.CurrentTurnID {
+			state.Winner = userID
+		}
+	}
+	return true
+}
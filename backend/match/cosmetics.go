@@ -0,0 +1,156 @@
+package match
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// Cosmetic slots. A player equips at most one item per slot.
+const (
+	SlotMarker       = "marker"
+	SlotBoardTheme   = "board_theme"
+	SlotWinAnimation = "win_animation"
+)
+
+// Storage location of each player's cosmetic inventory. Players can read
+// their own inventory; only the server writes it.
+const (
+	CosmeticsCollection = "cosmetics"
+	CosmeticsKey        = "inventory"
+)
+
+// LedgerPurchase is the wallet ledger reason for store purchases.
+const LedgerPurchase = "purchase"
+
+// CosmeticItem is one entry in the store catalogue. Items with a zero price
+// are owned by everyone.
+type CosmeticItem struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Slot  string `json:"slot"`
+	Price int64  `json:"price"`
+}
+
+// Cosmetics is the store catalogue, grouped by slot.
+var Cosmetics = []CosmeticItem{
+	{ID: "marker_classic", Name: "Classic", Slot: SlotMarker},
+	{ID: "marker_neon", Name: "Neon", Slot: SlotMarker, Price: 200},
+	{ID: "marker_chalk", Name: "Chalk", Slot: SlotMarker, Price: 150},
+	{ID: "marker_pixel", Name: "Pixel", Slot: SlotMarker, Price: 300},
+
+	{ID: "board_default", Name: "Default", Slot: SlotBoardTheme},
+	{ID: "board_wood", Name: "Wood", Slot: SlotBoardTheme, Price: 250},
+	{ID: "board_space", Name: "Deep Space", Slot: SlotBoardTheme, Price: 400},
+
+	{ID: "win_none", Name: "None", Slot: SlotWinAnimation},
+	{ID: "win_confetti", Name: "Confetti", Slot: SlotWinAnimation, Price: 300},
+	{ID: "win_fireworks", Name: "Fireworks", Slot: SlotWinAnimation, Price: 500},
+}
+
+// Inventory is a player's owned and equipped cosmetics.
+type Inventory struct {
+	Owned    []string          `json:"owned"`
+	Equipped map[string]string `json:"equipped"`
+}
+
+// Owns reports whether the item is free or in the inventory.
+func (inv *Inventory) Owns(item CosmeticItem) bool {
+	if item.Price == 0 {
+		return true
+	}
+	for _, id := range inv.Owned {
+		if id == item.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// EquippedItem returns the item equipped in slot, falling back to the
+// slot's free default.
+func (inv *Inventory) EquippedItem(slot string) string {
+	if id, ok := inv.Equipped[slot]; ok {
+		return id
+	}
+	for _, item := range Cosmetics {
+		if item.Slot == slot && item.Price == 0 {
+			return item.ID
+		}
+	}
+	return ""
+}
+
+// FindCosmetic looks up a catalogue item by ID.
+func FindCosmetic(id string) (CosmeticItem, bool) {
+	for _, item := range Cosmetics {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return CosmeticItem{}, false
+}
+
+// LoadInventory reads a player's inventory along with its storage version
+// for conditional writes. A player with no inventory gets an empty one and
+// an empty version.
+func LoadInventory(ctx context.Context, nk runtime.NakamaModule, userID string) (*Inventory, string, error) {
+	inv := &Inventory{Owned: []string{}, Equipped: map[string]string{}}
+
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: CosmeticsCollection,
+		Key:        CosmeticsKey,
+		UserID:     userID,
+	}})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objects) == 0 {
+		return inv, "", nil
+	}
+
+	if err := json.Unmarshal([]byte(objects[0].GetValue()), inv); err != nil {
+		return nil, "", fmt.Errorf("corrupt inventory: %w", err)
+	}
+	if inv.Equipped == nil {
+		inv.Equipped = map[string]string{}
+	}
+	return inv, objects[0].GetVersion(), nil
+}
+
+// InventoryWrite builds the storage write for an inventory. An empty
+// version means the object must not exist yet.
+func InventoryWrite(userID string, inv *Inventory, version string) (*runtime.StorageWrite, error) {
+	value, err := json.Marshal(inv)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = "*"
+	}
+	return &runtime.StorageWrite{
+		Collection:      CosmeticsCollection,
+		Key:             CosmeticsKey,
+		UserID:          userID,
+		Value:           string(value),
+		Version:         version,
+		PermissionRead:  1,
+		PermissionWrite: 0,
+	}, nil
+}
+
+// loadCosmetics fills in the player's equipped cosmetics for the state
+// broadcast. They are presentation only; the board always holds X and O.
+func (s *GameService) loadCosmetics(ctx context.Context, player *PlayerData) {
+	inv, _, err := LoadInventory(ctx, s.nk, player.UserID)
+	if err != nil {
+		s.logger.Warn("Cosmetics load failed for %s: %v", player.UserID, err)
+		inv = &Inventory{}
+	}
+
+	player.MarkerSkin = inv.EquippedItem(SlotMarker)
+	player.BoardTheme = inv.EquippedItem(SlotBoardTheme)
+	player.WinAnimation = inv.EquippedItem(SlotWinAnimation)
+}
@@ -0,0 +1,76 @@
+package match
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Emote is a server-defined quick-chat message. Clients send only the ID,
+// so nothing a player types is ever broadcast.
+type Emote struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Emotes is the fixed quick-chat set.
+var Emotes = []Emote{
+	{ID: "hello", Text: "Hello!"},
+	{ID: "good_luck", Text: "Good luck!"},
+	{ID: "nice_move", Text: "Nice move"},
+	{ID: "thinking", Text: "🤔"},
+	{ID: "oops", Text: "Oops!"},
+	{ID: "thumbs_up", Text: "👍"},
+	{ID: "wow", Text: "😮"},
+	{ID: "good_game", Text: "Good game"},
+	{ID: "rematch", Text: "Rematch?"},
+}
+
+// FindEmote looks up an emote by ID.
+func FindEmote(id string) (Emote, bool) {
+	for _, e := range Emotes {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return Emote{}, false
+}
+
+// handleEmote broadcasts a quick-chat emote with its sender. Emotes skip
+// the word filter and chat bans but are rate-limited and respect mutes.
+func (s *GameService) handleEmote(state *MatchState, userID string, data map[string]any) (string, error) {
+	player, ok := state.Players[userID]
+	if !ok {
+		return "", fmt.Errorf("player not found")
+	}
+
+	id, _ := data["emote_id"].(string)
+	emote, ok := FindEmote(id)
+	if !ok {
+		chatErr := &ChatError{Code: ChatErrBadEmote, Message: fmt.Sprintf("unknown emote: %s", id)}
+		s.sendChatError(state, userID, chatErr)
+		return "", chatErr
+	}
+
+	now := time.Now().Unix()
+	if !state.allowEmote(userID, now) {
+		chatErr := &ChatError{Code: ChatErrRateLimited, Message: fmt.Sprintf("at most %d emotes every %d seconds", EmoteRateLimit, EmoteRateWindowSecs)}
+		s.sendChatError(state, userID, chatErr)
+		return "", chatErr
+	}
+
+	payload, _ := json.Marshal(map[string]any{
+		"type":      "emote",
+		"emote_id":  emote.ID,
+		"text":      emote.Text,
+		"sender":    player.Username,
+		"sender_id": userID,
+		"timestamp": now,
+	})
+
+	// A non-nil empty recipient list means everyone muted the sender.
+	if recipients := state.chatRecipients(userID); recipients == nil || len(recipients) > 0 {
+		s.dispatcher.BroadcastMessage(OpCodeEmote, payload, recipients, nil, true)
+	}
+	return "emote_sent", nil
+}
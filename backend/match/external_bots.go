@@ -0,0 +1,252 @@
+package match
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+// External bots are Nakama accounts an admin registered as bots. They
+// connect over the normal socket, join with BotJoinMetadataKey set, and get
+// a BotView instead of the full state. Games with a bot in them are never
+// rated for humans; games between two bots move the bot ladder instead.
+
+// BotView is the simplified state sent to an external bot, personalised to
+// its seat. Deadline is when it must have moved. In vanishing games
+// VanishNext is the bot's own mark that goes when it next moves.
+type BotView struct {
+	MatchID    string            `json:"match_id"`
+	Board      [BoardSize]string `json:"board"`
+	Symbol     string            `json:"symbol"`
+	YourTurn   bool              `json:"your_turn"`
+	MoveCount  int               `json:"move_count"`
+	Deadline   int64             `json:"deadline,omitempty"`
+	GameOver   bool              `json:"game_over"`
+	Winner     string            `json:"winner,omitempty"`
+	IsDraw     bool              `json:"is_draw"`
+	VanishNext *int              `json:"vanish_next,omitempty"`
+}
+
+// ExternalBot reports whether the seat is played by a registered bot
+// account rather than the server or a human.
+func (p *PlayerData) ExternalBot() bool {
+	return p.IsBot && p.BotDifficulty == ""
+}
+
+// validateBotSeat checks the join flag against the bot registry: a
+// registered bot must join flagged and nobody else may. Bots stay out of
+// wagered, arena, clan war and server bot matches.
+func (s *GameService) validateBotSeat(ctx context.Context, state *MatchState, userID string, metadata map[string]string) ValidationResult {
+	flagged := metadata[BotJoinMetadataKey] == "true"
+
+	bot, err := dbpkg.NewRepository(s.db).GetBotAccount(ctx, userID)
+	switch {
+	case err == sql.ErrNoRows:
+		if flagged {
+			return ValidationResult{Valid: false, Message: "not a registered bot"}
+		}
+		return ValidationResult{Valid: true}
+	case err != nil:
+		s.logger.Error("Bot lookup failed for %s: %v", userID, err)
+		return ValidationResult{Valid: false, Message: "bot registry unavailable"}
+	case !flagged:
+		return ValidationResult{Valid: false, Message: "bot accounts must join as bots"}
+	case !bot.Active:
+		return ValidationResult{Valid: false, Message: "bot is deactivated"}
+	case state.Stake > 0 || state.ArenaID != "" || state.ClanWarID != "" || state.BotDifficulty != "":
+		return ValidationResult{Valid: false, Message: "bots cannot join this match"}
+	}
+	return ValidationResult{Valid: true}
+}
+
+// expectBot marks a validated bot so HandlePlayerJoin seats it as one.
+func (ms *MatchState) expectBot(userID string) {
+	if ms.botSeats == nil {
+		ms.botSeats = make(map[string]bool)
+	}
+	ms.botSeats[userID] = true
+}
+
+// loadBotStats fills in a bot's record from the bot registry. Its rating is
+// its ladder rating.
+func (s *GameService) loadBotStats(ctx context.Context, player *PlayerData) {
+	bot, err := dbpkg.NewRepository(s.db).GetBotAccount(ctx, player.UserID)
+	if err != nil {
+		s.logger.Warn("Failed to load bot record for %s: %v", player.UserID, err)
+		player.Rating = dbpkg.DefaultSkillRating
+		return
+	}
+	applyBotStats(player, bot)
+}
+
+func applyBotStats(player *PlayerData, bot *dbpkg.BotAccount) {
+	player.Wins = bot.Wins
+	player.Losses = bot.Losses
+	player.Draws = bot.Draws
+	player.Rating = bot.LadderRating
+}
+
+// botView builds the simplified state for the bot in userID's seat.
+func (ms *MatchState) botView(userID string) BotView {
+	view := BotView{
+		MatchID:   ms.MatchID,
+		Board:     ms.Board,
+		YourTurn:  !ms.GameOver && ms.CurrentTurnID == userID,
+		MoveCount: ms.MoveCount,
+		GameOver:  ms.GameOver,
+		IsDraw:    ms.IsDraw,
+	}
+	if player, ok := ms.Players[userID]; ok {
+		view.Symbol = player.Symbol
+	}
+	if next, ok := ms.VanishNext[userID]; ok {
+		view.VanishNext = &next
+	}
+	if winner, ok := ms.Players[ms.Winner]; ok {
+		view.Winner = winner.Symbol
+	}
+	if view.YourTurn && ms.TurnStartTime > 0 {
+		view.Deadline = ms.TurnStartTime + BotMoveTimeoutSecs
+	}
+	return view
+}
+
+// splitPresences separates connected humans from external bots.
+func (ms *MatchState) splitPresences() (humans, bots []runtime.Presence) {
+	for userID, presence := range ms.presences {
+		if player, ok := ms.Players[userID]; ok && player.ExternalBot() {
+			bots = append(bots, presence)
+		} else {
+			humans = append(humans, presence)
+		}
+	}
+	return humans, bots
+}
+
+// sendBotViews sends each connected external bot its view of the state.
+func (s *GameService) sendBotViews(state *MatchState, opCode int64, bots []runtime.Presence) {
+	for _, presence := range bots {
+		payload, err := json.Marshal(state.botView(presence.GetUserId()))
+		if err != nil {
+			s.logger.Error("Failed to marshal bot view: %v", err)
+			continue
+		}
+		s.dispatcher.BroadcastMessage(opCode, payload, []runtime.Presence{presence}, nil, true)
+	}
+}
+
+// allowBotMessage rate-limits the messages of external bots. Humans are
+// never limited here.
+func (ms *MatchState) allowBotMessage(userID string) bool {
+	player, ok := ms.Players[userID]
+	if !ok || !player.ExternalBot() {
+		return true
+	}
+	if ms.botMessageTimes == nil {
+		ms.botMessageTimes = make(map[string][]int64)
+	}
+	return allowRate(ms.botMessageTimes, userID, time.Now().Unix(), BotMessageRateLimit, BotMessageRateWindowSecs)
+}
+
+// overdueBot returns the external bot to move if it has run out of time.
+func (ms *MatchState) overdueBot() (*PlayerData, bool) {
+	if ms.GameOver || len(ms.Players) < MaxPlayers || ms.TurnStartTime == 0 {
+		return nil, false
+	}
+	player, ok := ms.Players[ms.CurrentTurnID]
+	if !ok || !player.ExternalBot() {
+		return nil, false
+	}
+	return player, time.Now().Unix()-ms.TurnStartTime > BotMoveTimeoutSecs
+}
+
+// forfeitBot ends the game against a bot that missed its move deadline.
+func (s *GameService) forfeitBot(ctx context.Context, state *MatchState, bot *PlayerData) {
+	s.logger.Info("Bot %s missed its move deadline in %s", bot.Username, state.MatchID)
+	state.GameOver = true
+	state.Forfeit = true
+	for userID := range state.Players {
+		if userID != bot.UserID {
+			state.Winner = userID
+		}
+	}
+	s.RecordResult(ctx, state)
+	s.broadcastState(state, OpCodeGameEnd)
+}
+
+// updateBotStats adds the game to every external bot's record. Games
+// between two bots also move their ladder ratings and the bot ladder.
+func (s *GameService) updateBotStats(ctx context.Context, state *MatchState) {
+	var bots []*PlayerData
+	for _, p := range state.Players {
+		if p.ExternalBot() {
+			bots = append(bots, p)
+		}
+	}
+	if len(bots) == 0 {
+		return
+	}
+
+	repo := dbpkg.NewRepository(s.db)
+	ladder := len(bots) == MaxPlayers
+	deltas := map[string]int{}
+	if ladder {
+		a, b := bots[0], bots[1]
+		ratings := map[string]int{a.UserID: a.Rating, b.UserID: b.Rating}
+		for _, bot := range bots {
+			if account, err := repo.GetBotAccount(ctx, bot.UserID); err == nil {
+				ratings[bot.UserID] = account.LadderRating
+			}
+		}
+		delta := eloDelta(ratings[a.UserID], ratings[b.UserID], playerOutcome(state, a.UserID))
+		deltas[a.UserID] = delta
+		deltas[b.UserID] = -delta
+	}
+
+	results := make([]dbpkg.GameResult, 0, len(bots))
+	for _, bot := range bots {
+		results = append(results, dbpkg.GameResult{
+			UserID:      bot.UserID,
+			Outcome:     playerOutcome(state, bot.UserID),
+			RatingDelta: deltas[bot.UserID],
+		})
+	}
+
+	updated, err := repo.RecordBotResults(ctx, results)
+	if err != nil {
+		s.logger.Error("Failed to persist bot results: %v", err)
+		return
+	}
+	for _, bot := range bots {
+		account, ok := updated[bot.UserID]
+		if !ok {
+			continue
+		}
+		applyBotStats(bot, account)
+		if !ladder {
+			continue
+		}
+		if _, err := s.nk.LeaderboardRecordWrite(context.Background(), LeaderboardBotLadder, bot.UserID, bot.Username, int64(account.LadderRating), 0, nil, nil); err != nil {
+			s.logger.Error("Bot ladder write failed for %s: %v", bot.UserID, err)
+		}
+	}
+}
+
+// eloDelta is the rating change of a player rated ratingA against ratingB
+// for outcome; the opponent's change is its negation.
+func eloDelta(ratingA, ratingB int, outcome string) int {
+	expected := 1 / (1 + math.Pow(10, float64(ratingB-ratingA)/400))
+	score := 0.0
+	switch outcome {
+	case dbpkg.OutcomeWin:
+		score = 1
+	case dbpkg.OutcomeDraw:
+		score = 0.5
+	}
+	return int(math.Round(EloKFactor * (score - expected)))
+}
@@ -0,0 +1,49 @@
+package match
+
+import (
+	"testing"
+
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+func TestEloDelta(t *testing.T) {
+	tests := []struct {
+		name             string
+		ratingA, ratingB int
+		outcome          string
+		want             int
+	}{
+		{"equal win", 1000, 1000, dbpkg.OutcomeWin, 16},
+		{"equal loss", 1000, 1000, dbpkg.OutcomeLoss, -16},
+		{"equal draw", 1000, 1000, dbpkg.OutcomeDraw, 0},
+		{"favourite wins", 1200, 1000, dbpkg.OutcomeWin, 8},
+		{"favourite loses", 1200, 1000, dbpkg.OutcomeLoss, -24},
+		{"underdog wins", 1000, 1200, dbpkg.OutcomeWin, 24},
+		{"underdog draws", 1000, 1200, dbpkg.OutcomeDraw, 8},
+		{"huge gap win", 2400, 1000, dbpkg.OutcomeWin, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eloDelta(tt.ratingA, tt.ratingB, tt.outcome); got != tt.want {
+				t.Errorf("eloDelta(%d, %d, %q) = %d, want %d", tt.ratingA, tt.ratingB, tt.outcome, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEloDeltaIsZeroSum(t *testing.T) {
+	opposite := map[string]string{
+		dbpkg.OutcomeWin:  dbpkg.OutcomeLoss,
+		dbpkg.OutcomeLoss: dbpkg.OutcomeWin,
+		dbpkg.OutcomeDraw: dbpkg.OutcomeDraw,
+	}
+	for _, ratings := range [][2]int{{1000, 1000}, {1000, 1150}, {1500, 900}} {
+		for outcome, other := range opposite {
+			a := eloDelta(ratings[0], ratings[1], outcome)
+			b := eloDelta(ratings[1], ratings[0], other)
+			if a != -b {
+				t.Errorf("ratings %v, %s: deltas %d and %d do not cancel", ratings, outcome, a, b)
+			}
+		}
+	}
+}
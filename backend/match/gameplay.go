@@ -3,6 +3,7 @@ package match
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // ProcessMove validates a move, applies it, checks for a winner, and broadcasts the updated state.
@@ -16,11 +17,9 @@ func (s *GameService) ProcessMove(ctx context.Context, state *MatchState, userID
 		return fmt.Errorf("player not in match: %s", userID)
 	}
 
-	state.Board[position] = player.Symbol
-	state.MoveCount++
+	winner, isDraw := placeMark(state, player, position)
 	s.logger.Info("Move: %s placed %s at %d", player.Username, player.Symbol, position)
 
-	winner, isDraw := CheckWinner(state)
 	if winner != "" || isDraw {
 		s.finishGame(ctx, state, winner, isDraw)
 	} else {
@@ -46,11 +45,9 @@ func (s *GameService) HandleTimeout(ctx context.Context, state *MatchState) {
 		return
 	}
 
-	state.Board[autoPos] = player.Symbol
-	state.MoveCount++
+	winner, isDraw := placeMark(state, player, autoPos)
 	s.logger.Info("Auto-move: %s at %d", player.Symbol, autoPos)
 
-	winner, isDraw := CheckWinner(state)
 	if winner != "" || isDraw {
 		s.finishGame(ctx, state, winner, isDraw)
 	} else {
@@ -65,15 +62,60 @@ func (s *GameService) finishGame(ctx context.Context, state *MatchState, winner
 	state.Winner = winner
 	state.IsDraw = isDraw
 
-	for playerID := range state.Players {
-		s.updatePlayerStats(ctx, state, playerID, playerID == winner)
-	}
-
-	s.recordMatchHistory(ctx, state)
+	s.RecordResult(ctx, state)
 	s.broadcastState(state, OpCodeGameEnd)
 	s.logger.Info("Game ended — winner: %s, draw: %v", winner, isDraw)
 }
 
+// RecordResult is the single game-end path for finished and forfeited
+// games: it updates player stats, match history, any arena standing,
+// achievements, and XP/quest progression. It never broadcasts, so it is
+// safe on a service without a dispatcher.
+func (s *GameService) RecordResult(ctx context.Context, state *MatchState) {
+	s.updatePlayerStats(ctx, state)
+	s.recordMatchHistory(ctx, state)
+	s.recordArenaResult(ctx, state)
+	s.recordClanWarResult(ctx, state)
+	s.awardWinCoins(ctx, state)
+	s.settleWager(ctx, state)
+	s.trainLearningBot(ctx, state)
+
+	events := gameEndEvents(state)
+	s.checkAchievements(ctx, events)
+	s.updateProgression(ctx, events)
+}
+
+// placeMark puts the player's symbol on the board and reports the outcome.
+// In a vanishing game the player's oldest mark goes first if they are at
+// the limit. Callers must validate the position first.
+func placeMark(state *MatchState, player *PlayerData, position int) (winner string, isDraw bool) {
+	move := MoveRecord{
+		UserID:    player.UserID,
+		Symbol:    player.Symbol,
+		Position:  position,
+		Timestamp: time.Now().Unix(),
+	}
+	if state.Vanishing() {
+		if vanished, ok := state.vanishOldest(player); ok {
+			move.Vanished = &vanished
+		}
+	}
+
+	state.Board[position] = player.Symbol
+	state.MoveCount++
+	state.Moves = append(state.Moves, move)
+	if !state.Vanishing() {
+		return CheckWinner(state)
+	}
+
+	state.trackPiece(player, position)
+	winner, isDraw = CheckWinner(state)
+	if isDraw {
+		state.DrawReason = state.vanishingDraw()
+	}
+	return winner, isDraw
+}
+
 func findFirstEmptyCell(state *MatchState) int {
 	for i := 0; i < BoardSize; i++ {
 		if state.Board[i] == "" {
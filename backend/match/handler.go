@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -12,15 +14,63 @@ import (
 // MatchInit sets up a new match with the requested game mode.
 func (m *Match) MatchInit(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, params map[string]interface{}) (interface{}, int, string) {
 	mode := ModeClassic
-	if modeParam, ok := params["mode"].(string); ok && modeParam == ModeTimed {
-		mode = ModeTimed
+	if modeParam, ok := params["mode"].(string); ok && (modeParam == ModeTimed || modeParam == ModeMisere || modeParam == ModeVanishing) {
+		mode = modeParam
 	}
 
 	state := NewGameState(mode)
-	label := fmt.Sprintf("mode:%s", mode)
+	if arenaID, ok := params["arena_id"].(string); ok {
+		state.ArenaID = arenaID
+	}
+	if warID, ok := params["clan_war_id"].(string); ok {
+		state.ClanWarID = warID
+	}
+	if reserved, ok := params["reserved_for"].(string); ok && reserved != "" {
+		state.ReservedFor = strings.Split(reserved, ",")
+	}
+	if emotesOnly, ok := params["emotes_only"].(bool); ok {
+		state.EmotesOnly = emotesOnly
+	}
+	switch stake := params["stake"].(type) {
+	case int64:
+		state.Stake = stake
+	case int:
+		state.Stake = int64(stake)
+	case float64:
+		state.Stake = int64(stake)
+	}
+	// A bot game is always casual; arena, clan war and wagered games are
+	// always rated. The server's bots play with the solver, which knows
+	// nothing of vanishing marks, so vanishing games get neither a bot nor
+	// bot-fill.
+	if bot, ok := params["bot"].(string); ok && IsBotDifficulty(bot) && !state.Vanishing() && state.ArenaID == "" && state.ClanWarID == "" && state.Stake == 0 {
+		state.BotDifficulty = bot
+		state.Casual = true
+	} else if casual, ok := params["casual"].(bool); ok && state.ArenaID == "" && state.ClanWarID == "" && state.Stake == 0 {
+		state.Casual = casual
+	}
+	// Bot-fill is for open matches only.
+	if state.BotDifficulty == "" && !state.Vanishing() && state.Stake == 0 && state.ArenaID == "" && state.ClanWarID == "" && len(state.ReservedFor) == 0 {
+		switch secs := params["bot_fill_secs"].(type) {
+		case int:
+			state.BotFillSecs = secs
+		case int64:
+			state.BotFillSecs = int(secs)
+		case float64:
+			state.BotFillSecs = int(secs)
+		}
+	}
 
 	logger.Info("Match initialized — mode: %s", mode)
-	return state, TickRate, label
+	return state, TickRate, matchLabel(state)
+}
+
+// matchLabel describes the match for match listings. Bot games say so.
+func matchLabel(state *MatchState) string {
+	if state.BotDifficulty != "" {
+		return fmt.Sprintf("mode:%s bot:%s", state.Mode, state.BotDifficulty)
+	}
+	return fmt.Sprintf("mode:%s", state.Mode)
 }
 
 // MatchJoinAttempt decides whether a player is allowed to join.
@@ -28,6 +78,10 @@ func (m *Match) MatchJoinAttempt(ctx context.Context, logger runtime.Logger, db
 	gameState := state.(*MatchState)
 	m.ensureService(logger, db, nk, dispatcher)
 
+	if gameState.canRejoin(presence.GetUserId()) {
+		return state, true, ""
+	}
+
 	if len(gameState.Players) >= MaxPlayers {
 		return state, false, "match is full"
 	}
@@ -41,6 +95,10 @@ func (m *Match) MatchJoinAttempt(ctx context.Context, logger runtime.Logger, db
 		return state, false, "game in progress"
 	}
 
+	if metadata[BotJoinMetadataKey] == "true" {
+		gameState.expectBot(presence.GetUserId())
+	}
+
 	return state, true, ""
 }
 
@@ -49,15 +107,10 @@ func (m *Match) MatchJoin(ctx context.Context, logger runtime.Logger, db *sql.DB
 	gameState := state.(*MatchState)
 	m.ensureService(logger, db, nk, dispatcher)
 
-	// Capture the match ID so we can reference it later (e.g. history).
-	if gameState.MatchID == "" {
-		if matchID, ok := ctx.Value(runtime.RUNTIME_CTX_MATCH_ID).(string); ok {
-			gameState.MatchID = matchID
-		}
-	}
+	gameState.captureMatchID(ctx)
 
 	for _, presence := range presences {
-		if err := m.service.HandlePlayerJoin(gameState, presence, tick); err != nil {
+		if err := m.service.HandlePlayerJoin(ctx, gameState, presence, tick); err != nil {
 			logger.Error("Player join failed: %v", err)
 		}
 	}
@@ -82,6 +135,21 @@ func (m *Match) MatchLoop(ctx context.Context, logger runtime.Logger, db *sql.DB
 	gameState := state.(*MatchState)
 	m.ensureService(logger, db, nk, dispatcher)
 
+	// Paired matches close when a player never shows up.
+	if gameState.pairingExpired(time.Now().Unix()) {
+		gameState.captureMatchID(ctx)
+		m.service.releasePairing(ctx, gameState, gameState.noShows())
+	}
+	if gameState.closed {
+		return nil
+	}
+
+	// External bots forfeit when they miss their move deadline.
+	if bot, overdue := gameState.overdueBot(); overdue {
+		m.service.forfeitBot(ctx, gameState, bot)
+		return gameState
+	}
+
 	// Auto-move on timeout in timed mode.
 	if gameState.Mode == ModeTimed && !gameState.GameOver && len(gameState.Players) == MaxPlayers {
 		if gameState.IsTimedOut() {
@@ -90,7 +158,16 @@ func (m *Match) MatchLoop(ctx context.Context, logger runtime.Logger, db *sql.DB
 		}
 	}
 
+	m.service.offerBot(gameState)
+	m.service.takeOverAbandonedSeats(ctx, gameState)
+	m.service.playBotMove(ctx, gameState, tick)
+
 	for _, message := range messages {
+		if !gameState.allowBotMessage(message.GetUserId()) {
+			logger.Warn("Bot %s is over its message rate limit", message.GetUserId())
+			continue
+		}
+
 		switch message.GetOpCode() {
 		case OpCodeMove:
 			var move MoveMessage
@@ -111,16 +188,53 @@ func (m *Match) MatchLoop(ctx context.Context, logger runtime.Logger, db *sql.DB
 			if _, err := m.service.handleChatMessage(ctx, gameState, message.GetUserId(), chatData); err != nil {
 				logger.Error("Chat handling failed: %v", err)
 			}
+
+		case OpCodeEmote:
+			var emoteData map[string]any
+			if err := json.Unmarshal(message.GetData(), &emoteData); err != nil {
+				logger.Error("Bad emote payload: %v", err)
+				continue
+			}
+			if _, err := m.service.handleEmote(gameState, message.GetUserId(), emoteData); err != nil {
+				logger.Warn("Emote handling failed: %v", err)
+			}
+
+		case OpCodeHint:
+			// Checked here rather than trusted from the client: rated games
+			// never get hints.
+			if err := m.service.HandleHintRequest(gameState, message.GetUserId()); err != nil {
+				logger.Warn("Hint refused for %s: %v", message.GetUserId(), err)
+			}
+
+		case OpCodeBotOffer:
+			var reply BotOfferReply
+			if err := json.Unmarshal(message.GetData(), &reply); err != nil {
+				logger.Error("Bad bot offer reply: %v", err)
+				continue
+			}
+			if err := m.service.HandleBotOfferReply(ctx, gameState, message.GetUserId(), reply); err != nil {
+				logger.Warn("Bot offer reply from %s: %v", message.GetUserId(), err)
+			}
 		}
 	}
 
 	return gameState
 }
 
-// MatchTerminate is called when the server shuts the match down.
+// MatchTerminate is called when the server shuts the match down. A wager
+// still in escrow is refunded and paired players are released since the
+// game was abandoned.
 func (m *Match) MatchTerminate(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, dispatcher runtime.MatchDispatcher, tick int64, state interface{}, graceSeconds int) interface{} {
+	gameState := state.(*MatchState)
+	m.ensureService(logger, db, nk, dispatcher)
+
+	m.service.refundWager(ctx, gameState)
+	if !gameState.GameOver {
+		gameState.captureMatchID(ctx)
+		m.service.releasePairing(ctx, gameState, gameState.ReservedFor)
+	}
 	logger.Info("Match terminated")
-	return state
+	return gameState
 }
 
 // MatchSignal handles custom client-to-server signals (rematch, chat, etc.).
@@ -128,6 +242,7 @@ func (m *Match) MatchSignal(ctx context.Context, logger runtime.Logger, db *sql.
 	m.ensureService(logger, db, nk, dispatcher)
 
 	gameState := state.(*MatchState)
+	gameState.captureMatchID(ctx)
 
 	var signalData struct {
 		UserID string `json:"userId"`
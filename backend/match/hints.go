@@ -0,0 +1,87 @@
+package match
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/prasanth-33460/tic-tac-toe/backend/solver"
+)
+
+// HintResponse answers an OpCodeHint request. Error is set instead of
+// Position when the hint was refused.
+type HintResponse struct {
+	Position  int    `json:"position"`
+	HintsLeft int    `json:"hints_left"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HandleHintRequest sends the player to move the solver's best move. Hints
+// are refused in rated and vanishing games and limited to MaxHintsPerGame
+// per player.
+func (s *GameService) HandleHintRequest(state *MatchState, userID string) error {
+	err := s.sendHint(state, userID)
+	if err != nil {
+		s.replyHint(state, userID, HintResponse{Position: -1, Error: err.Error()})
+	}
+	return err
+}
+
+func (s *GameService) sendHint(state *MatchState, userID string) error {
+	player, ok := state.Players[userID]
+	switch {
+	case !ok:
+		return fmt.Errorf("player not in match")
+	case player.IsBot:
+		return fmt.Errorf("bots cannot use hints")
+	case state.Rated():
+		return fmt.Errorf("hints are not allowed in rated games")
+	case state.Vanishing():
+		return fmt.Errorf("hints are not available in vanishing games")
+	case state.GameOver:
+		return fmt.Errorf("game has already ended")
+	case state.CurrentTurnID != userID:
+		return fmt.Errorf("not your turn")
+	case player.HintsUsed >= MaxHintsPerGame:
+		return fmt.Errorf("no hints left this game")
+	}
+
+	board, err := state.solverBoard()
+	if err != nil {
+		return err
+	}
+	analysis, err := solver.Analyze(board, player.Symbol, 0)
+	if err != nil {
+		return err
+	}
+	if len(analysis.BestMoves) == 0 {
+		return fmt.Errorf("no moves available")
+	}
+
+	player.HintsUsed++
+	s.logger.Info("Hint for %s: %d (%d used)", player.Username, analysis.BestMoves[0], player.HintsUsed)
+	s.replyHint(state, userID, HintResponse{
+		Position:  analysis.BestMoves[0],
+		HintsLeft: MaxHintsPerGame - player.HintsUsed,
+	})
+	return nil
+}
+
+func (s *GameService) replyHint(state *MatchState, userID string, resp HintResponse) {
+	presence, ok := state.presences[userID]
+	if !ok {
+		return
+	}
+	payload, _ := json.Marshal(resp)
+	s.dispatcher.BroadcastMessage(OpCodeHint, payload, []runtime.Presence{presence}, nil, true)
+}
+
+// solverBoard returns the match board under the match's rules.
+func (ms *MatchState) solverBoard() (*solver.Board, error) {
+	board, err := solver.NewBoard(append([]string(nil), ms.Board[:]...), 0)
+	if err != nil {
+		return nil, err
+	}
+	board.Misere = ms.Misere()
+	return board, nil
+}
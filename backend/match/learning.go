@@ -0,0 +1,281 @@
+package match
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+// The learning bot is a MENACE-style matchbox learner: every position it
+// has seen holds a count of beads per cell, it plays a cell with
+// probability proportional to its beads, and after every finished game the
+// beads of the moves it played are added or taken away. Its weights and
+// results live in system-owned Nakama storage.
+const (
+	LearningCollection = "bots"
+	learningBrainKey   = "learning_brain"
+	learningStatsKey   = "learning_stats"
+
+	// learningInitialBeads is what each empty cell holds the first time a
+	// position is seen.
+	learningInitialBeads = 3
+
+	// learningHistoryDays is how many daily buckets of results are kept.
+	learningHistoryDays = 90
+
+	// learningWriteRetries bounds retries when two matches train at once.
+	learningWriteRetries = 3
+)
+
+// learningReward is the beads added to every move the bot played, by the
+// bot's result. A cell never drops below one bead, so every legal move
+// stays possible.
+var learningReward = map[string]int{
+	dbpkg.OutcomeWin:  3,
+	dbpkg.OutcomeDraw: 1,
+	dbpkg.OutcomeLoss: -1,
+}
+
+// LearningBrain holds the bead counts, keyed by board from the bot's side:
+// "m" for its own marks, "t" for the opponent's and "-" for empty cells.
+type LearningBrain struct {
+	Boxes map[string][]int `json:"boxes"`
+}
+
+// LearningStats are the learning bot's results overall and per UTC day,
+// oldest day first.
+type LearningStats struct {
+	Games  int           `json:"games"`
+	Wins   int           `json:"wins"`
+	Draws  int           `json:"draws"`
+	Losses int           `json:"losses"`
+	Days   []LearningDay `json:"days"`
+}
+
+// LearningDay is one day's results.
+type LearningDay struct {
+	Date   string `json:"date"`
+	Games  int    `json:"games"`
+	Wins   int    `json:"wins"`
+	Draws  int    `json:"draws"`
+	Losses int    `json:"losses"`
+}
+
+// LoadLearningBrain reads the bot's weights with their storage version. A
+// bot that has never played gets an empty brain and an empty version.
+func LoadLearningBrain(ctx context.Context, nk runtime.NakamaModule) (*LearningBrain, string, error) {
+	brain := &LearningBrain{Boxes: map[string][]int{}}
+	version, err := readLearningObject(ctx, nk, learningBrainKey, brain)
+	if brain.Boxes == nil {
+		brain.Boxes = map[string][]int{}
+	}
+	return brain, version, err
+}
+
+// LoadLearningStats reads the bot's results with their storage version.
+func LoadLearningStats(ctx context.Context, nk runtime.NakamaModule) (*LearningStats, string, error) {
+	stats := &LearningStats{Days: []LearningDay{}}
+	version, err := readLearningObject(ctx, nk, learningStatsKey, stats)
+	return stats, version, err
+}
+
+func readLearningObject(ctx context.Context, nk runtime.NakamaModule, key string, value interface{}) (string, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: LearningCollection,
+		Key:        key,
+	}})
+	if err != nil {
+		return "", err
+	}
+	if len(objects) == 0 {
+		return "", nil
+	}
+	if err := json.Unmarshal([]byte(objects[0].GetValue()), value); err != nil {
+		return "", fmt.Errorf("corrupt %s: %w", key, err)
+	}
+	return objects[0].GetVersion(), nil
+}
+
+func learningWrite(key string, value interface{}, version string) (*runtime.StorageWrite, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if version == "" {
+		version = "*"
+	}
+	return &runtime.StorageWrite{
+		Collection:      LearningCollection,
+		Key:             key,
+		Value:           string(data),
+		Version:         version,
+		PermissionRead:  0,
+		PermissionWrite: 0,
+	}, nil
+}
+
+// learningKeyPrefix sets apart the positions of each rule set, so they
+// learn separately.
+var learningKeyPrefix = map[string]string{
+	ModeMisere:    "~",
+	ModeVanishing: "^",
+}
+
+// learningKey encodes the board from symbol's side under mode's rules.
+func learningKey(board [BoardSize]string, symbol, mode string) string {
+	key := make([]byte, 0, BoardSize+1)
+	key = append(key, learningKeyPrefix[mode]...)
+	for _, cell := range board {
+		switch cell {
+		case "":
+			key = append(key, '-')
+		case symbol:
+			key = append(key, 'm')
+		default:
+			key = append(key, 't')
+		}
+	}
+	return string(key)
+}
+
+// beads returns the bead counts for a position, filling in a fresh box
+// for positions the bot has not seen.
+func (b *LearningBrain) beads(board [BoardSize]string, key string) []int {
+	box, ok := b.Boxes[key]
+	if !ok || len(box) != BoardSize {
+		box = make([]int, BoardSize)
+		for i, cell := range board {
+			if cell == "" {
+				box[i] = learningInitialBeads
+			}
+		}
+		b.Boxes[key] = box
+	}
+	return box
+}
+
+// chooseLearningMove draws a bead from the current position's box.
+func (s *GameService) chooseLearningMove(ctx context.Context, state *MatchState, bot *PlayerData) (int, error) {
+	brain, _, err := LoadLearningBrain(ctx, s.nk)
+	if err != nil {
+		return -1, err
+	}
+
+	box := brain.beads(state.Board, learningKey(state.Board, bot.Symbol, state.Mode))
+	total := 0
+	for i, cell := range state.Board {
+		if cell == "" {
+			total += max(box[i], 1)
+		}
+	}
+	if total == 0 {
+		return -1, fmt.Errorf("no moves available")
+	}
+
+	draw := rand.Intn(total)
+	for i, cell := range state.Board {
+		if cell != "" {
+			continue
+		}
+		draw -= max(box[i], 1)
+		if draw < 0 {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no moves available")
+}
+
+// trainLearningBot reinforces the moves the learning bot played in the
+// finished game and adds the result to its stats. Forfeits are skipped:
+// the game was never played out, so there is nothing to learn from it.
+func (s *GameService) trainLearningBot(ctx context.Context, state *MatchState) {
+	var bot *PlayerData
+	for _, p := range state.Players {
+		if p.BotDifficulty == BotLearning {
+			bot = p
+		}
+	}
+	if bot == nil || state.Forfeit || (state.Winner == "" && !state.IsDraw) {
+		return
+	}
+
+	outcome := playerOutcome(state, bot.UserID)
+	for attempt := 0; attempt < learningWriteRetries; attempt++ {
+		err := s.saveLearningGame(ctx, state, bot, outcome)
+		if err == nil {
+			return
+		}
+		s.logger.Warn("Learning bot update failed (attempt %d): %v", attempt+1, err)
+	}
+	s.logger.Error("Learning bot update gave up for %s", state.MatchID)
+}
+
+// saveLearningGame applies one game to the stored brain and stats. Both
+// objects are written conditionally, so a concurrent update makes the
+// whole write fail and the caller retries from fresh copies.
+func (s *GameService) saveLearningGame(ctx context.Context, state *MatchState, bot *PlayerData, outcome string) error {
+	brain, brainVersion, err := LoadLearningBrain(ctx, s.nk)
+	if err != nil {
+		return err
+	}
+	stats, statsVersion, err := LoadLearningStats(ctx, s.nk)
+	if err != nil {
+		return err
+	}
+
+	var board [BoardSize]string
+	for _, m := range state.Moves {
+		if m.UserID == bot.UserID {
+			box := brain.beads(board, learningKey(board, bot.Symbol, state.Mode))
+			box[m.Position] = max(box[m.Position]+learningReward[outcome], 1)
+		}
+		if m.Vanished != nil {
+			board[*m.Vanished] = ""
+		}
+		board[m.Position] = m.Symbol
+	}
+
+	stats.record(time.Now().UTC().Format("2006-01-02"), outcome)
+
+	brainWrite, err := learningWrite(learningBrainKey, brain, brainVersion)
+	if err != nil {
+		return err
+	}
+	statsWrite, err := learningWrite(learningStatsKey, stats, statsVersion)
+	if err != nil {
+		return err
+	}
+	_, err = s.nk.StorageWrite(ctx, []*runtime.StorageWrite{brainWrite, statsWrite})
+	return err
+}
+
+// record adds one result to the totals and to date's bucket.
+func (st *LearningStats) record(date, outcome string) {
+	if n := len(st.Days); n == 0 || st.Days[n-1].Date != date {
+		st.Days = append(st.Days, LearningDay{Date: date})
+	}
+	day := &st.Days[len(st.Days)-1]
+
+	st.Games++
+	day.Games++
+	switch outcome {
+	case dbpkg.OutcomeWin:
+		st.Wins++
+		day.Wins++
+	case dbpkg.OutcomeDraw:
+		st.Draws++
+		day.Draws++
+	default:
+		st.Losses++
+		day.Losses++
+	}
+
+	if len(st.Days) > learningHistoryDays {
+		st.Days = st.Days[len(st.Days)-learningHistoryDays:]
+	}
+}
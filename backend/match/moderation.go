@@ -0,0 +1,307 @@
+package match
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+// Chat error codes sent with OpCodeChatError.
+const (
+	ChatErrTooLong     = "too_long"
+	ChatErrRateLimited = "rate_limited"
+	ChatErrFiltered    = "filtered"
+	ChatErrChatBanned  = "chat_banned"
+	ChatErrTextOff     = "text_chat_disabled"
+	ChatErrBadEmote    = "unknown_emote"
+)
+
+// Word filter modes.
+const (
+	ChatFilterMask   = "mask"
+	ChatFilterReject = "reject"
+)
+
+// Runtime env keys (Nakama's runtime.env config) for the word filter.
+// chat_filter_words is a comma-separated list replacing the default list.
+const (
+	envChatFilterWords = "chat_filter_words"
+	envChatFilterMode  = "chat_filter_mode"
+)
+
+// defaultChatFilterWords is used when chat_filter_words is not configured.
+var defaultChatFilterWords = []string{"fuck", "shit", "bitch", "cunt", "asshole", "bastard", "dick"}
+
+// ChatError is a chat message rejected by moderation. It is reported to
+// the sender through OpCodeChatError.
+type ChatError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ChatError) Error() string {
+	return e.Message
+}
+
+// ChatFilter masks or rejects messages containing blocked words. Words
+// match whole and case-insensitively.
+type ChatFilter struct {
+	pattern *regexp.Regexp
+	mode    string
+}
+
+var (
+	chatFilterOnce sync.Once
+	chatFilter     *ChatFilter
+)
+
+// chatFilterFromEnv builds the word filter from the runtime env the first
+// time it is needed. The env is fixed for the life of the server.
+func chatFilterFromEnv(ctx context.Context) *ChatFilter {
+	chatFilterOnce.Do(func() {
+		env, _ := ctx.Value(runtime.RUNTIME_CTX_ENV).(map[string]string)
+
+		words := defaultChatFilterWords
+		if configured, ok := env[envChatFilterWords]; ok {
+			words = strings.Split(configured, ",")
+		}
+		mode := ChatFilterMask
+		if env[envChatFilterMode] == ChatFilterReject {
+			mode = ChatFilterReject
+		}
+		chatFilter = NewChatFilter(words, mode)
+	})
+	return chatFilter
+}
+
+// NewChatFilter compiles a filter for the given words.
+func NewChatFilter(words []string, mode string) *ChatFilter {
+	var quoted []string
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+
+	f := &ChatFilter{mode: mode}
+	if len(quoted) > 0 {
+		f.pattern = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	}
+	return f
+}
+
+// Apply returns the message with blocked words masked, or ok=false if the
+// filter rejects messages containing them.
+func (f *ChatFilter) Apply(message string) (filtered string, ok bool) {
+	if f.pattern == nil || !f.pattern.MatchString(message) {
+		return message, true
+	}
+	if f.mode == ChatFilterReject {
+		return "", false
+	}
+	return f.pattern.ReplaceAllStringFunc(message, func(w string) string {
+		return strings.Repeat("*", len([]rune(w)))
+	}), true
+}
+
+// moderateChat runs a message through every chat check and returns the
+// text to broadcast.
+func (s *GameService) moderateChat(ctx context.Context, state *MatchState, userID, message string) (string, error) {
+	if state.EmotesOnly {
+		return "", &ChatError{Code: ChatErrTextOff, Message: "only emotes are allowed in this match"}
+	}
+	if len(message) == 0 || len(message) > MaxChatLength {
+		return "", &ChatError{Code: ChatErrTooLong, Message: fmt.Sprintf("message must be between 1-%d characters", MaxChatLength)}
+	}
+
+	if player, ok := state.Players[userID]; ok && player.chatBanned {
+		return "", &ChatError{Code: ChatErrChatBanned, Message: "you are banned from chat"}
+	}
+
+	if !state.allowChat(userID, time.Now().Unix()) {
+		return "", &ChatError{Code: ChatErrRateLimited, Message: fmt.Sprintf("at most %d messages every %d seconds", ChatRateLimit, ChatRateWindowSecs)}
+	}
+
+	filtered, ok := chatFilterFromEnv(ctx).Apply(message)
+	if !ok {
+		return "", &ChatError{Code: ChatErrFiltered, Message: "message contains blocked words"}
+	}
+	return filtered, nil
+}
+
+// allowChat records a message attempt and reports whether the player is
+// within the rate limit.
+func (ms *MatchState) allowChat(userID string, now int64) bool {
+	if ms.chatTimes == nil {
+		ms.chatTimes = make(map[string][]int64)
+	}
+	return allowRate(ms.chatTimes, userID, now, ChatRateLimit, ChatRateWindowSecs)
+}
+
+// allowEmote is allowChat for emotes, which have their own budget.
+func (ms *MatchState) allowEmote(userID string, now int64) bool {
+	if ms.emoteTimes == nil {
+		ms.emoteTimes = make(map[string][]int64)
+	}
+	return allowRate(ms.emoteTimes, userID, now, EmoteRateLimit, EmoteRateWindowSecs)
+}
+
+// allowRate records an attempt in times and reports whether the player
+// made fewer than limit attempts in the last window seconds.
+func allowRate(times map[string][]int64, userID string, now int64, limit int, window int64) bool {
+	recent := times[userID][:0]
+	for _, t := range times[userID] {
+		if now-t < window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= limit {
+		times[userID] = recent
+		return false
+	}
+	times[userID] = append(recent, now)
+	return true
+}
+
+// SetMuted mutes or unmutes target's chat for userID for the rest of the
+// match.
+func (ms *MatchState) SetMuted(userID, target string, muted bool) {
+	if ms.mutes == nil {
+		ms.mutes = make(map[string]map[string]bool)
+	}
+	if ms.mutes[userID] == nil {
+		ms.mutes[userID] = make(map[string]bool)
+	}
+	if muted {
+		ms.mutes[userID][target] = true
+	} else {
+		delete(ms.mutes[userID], target)
+	}
+}
+
+// chatRecipients returns the presences that should receive a message from
+// sender, or nil for everyone when nobody has muted them.
+func (ms *MatchState) chatRecipients(sender string) []runtime.Presence {
+	mutedBySomeone := false
+	for _, muted := range ms.mutes {
+		if muted[sender] {
+			mutedBySomeone = true
+		}
+	}
+	if !mutedBySomeone {
+		return nil
+	}
+
+	recipients := []runtime.Presence{}
+	for userID, presence := range ms.presences {
+		if !ms.mutes[userID][sender] {
+			recipients = append(recipients, presence)
+		}
+	}
+	return recipients
+}
+
+// handleMuteSignal mutes or unmutes the caller's opponent.
+func (s *GameService) handleMuteSignal(state *MatchState, userID string, data map[string]any) (string, error) {
+	if _, ok := state.Players[userID]; !ok {
+		return "", fmt.Errorf("player not found")
+	}
+	muted, ok := data["muted"].(bool)
+	if !ok {
+		muted = true
+	}
+
+	for opponentID := range state.Players {
+		if opponentID != userID {
+			state.SetMuted(userID, opponentID, muted)
+		}
+	}
+
+	if muted {
+		return "opponent_muted", nil
+	}
+	return "opponent_unmuted", nil
+}
+
+// loadChatBan reads the player's chat ban. It is checked on join and at
+// the start of each rematch rather than on every message; moderators'
+// changes in between arrive through handleChatBanSignal.
+func (s *GameService) loadChatBan(ctx context.Context, player *PlayerData) {
+	banned, err := dbpkg.NewRepository(s.db).IsChatBanned(ctx, player.UserID)
+	if err != nil {
+		s.logger.Warn("Chat ban lookup failed for %s: %v", player.UserID, err)
+		return
+	}
+	player.chatBanned = banned
+}
+
+// handleChatBanSignal applies a chat ban or unban a moderator just stored.
+// It is sent to every running match, so a player who is not in this one
+// is not an error.
+func (s *GameService) handleChatBanSignal(state *MatchState, userID string, data map[string]any) (string, error) {
+	player, ok := state.Players[userID]
+	if !ok {
+		return "not_in_match", nil
+	}
+	banned, _ := data["banned"].(bool)
+	player.chatBanned = banned
+	return "chat_ban_applied", nil
+}
+
+// handleBlockSignal hides a player the caller just blocked from their chat
+// for the rest of the match. The block list itself is stored by the RPC.
+func (s *GameService) handleBlockSignal(state *MatchState, userID string, data map[string]any) (string, error) {
+	target, _ := data["targetId"].(string)
+	if _, ok := state.Players[userID]; !ok {
+		return "", fmt.Errorf("player not found")
+	}
+	if _, ok := state.Players[target]; !ok || target == userID {
+		return "", fmt.Errorf("target not in match")
+	}
+
+	state.SetMuted(userID, target, true)
+	return "player_blocked", nil
+}
+
+// sendChatError tells the sender why their message was not delivered.
+func (s *GameService) sendChatError(state *MatchState, userID string, chatErr *ChatError) {
+	presence, ok := state.presences[userID]
+	if !ok {
+		return
+	}
+	payload, _ := json.Marshal(chatErr)
+	s.dispatcher.BroadcastMessage(OpCodeChatError, payload, []runtime.Presence{presence}, nil, true)
+}
+
+// ReportSnapshot is the evidence a live match attaches to a player report.
+// GameIndex is the game in progress, counting rematches.
+type ReportSnapshot struct {
+	Players   []string     `json:"players"`
+	GameIndex int          `json:"game_index"`
+	Moves     []MoveRecord `json:"moves"`
+}
+
+// handleReportSnapshot returns the players and moves so far to a
+// participant filing a report mid-game.
+func (s *GameService) handleReportSnapshot(state *MatchState, userID string) (string, error) {
+	if _, ok := state.Players[userID]; !ok {
+		return "", fmt.Errorf("not a participant")
+	}
+
+	snapshot := ReportSnapshot{Players: make([]string, 0, len(state.Players)), GameIndex: state.GameIndex, Moves: state.Moves}
+	for playerID := range state.Players {
+		snapshot.Players = append(snapshot.Players, playerID)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
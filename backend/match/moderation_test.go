@@ -0,0 +1,40 @@
+package match
+
+import "testing"
+
+func TestChatFilterApply(t *testing.T) {
+	words := []string{"darn", " heck ", "", "a.b"}
+
+	tests := []struct {
+		name    string
+		mode    string
+		message string
+		want    string
+		wantOK  bool
+	}{
+		{"clean message", ChatFilterMask, "good game", "good game", true},
+		{"masked word", ChatFilterMask, "darn it", "**** it", true},
+		{"case insensitive", ChatFilterMask, "DaRn it", "**** it", true},
+		{"trimmed word", ChatFilterMask, "what the heck", "what the ****", true},
+		{"every occurrence", ChatFilterMask, "darn darn", "**** ****", true},
+		{"whole words only", ChatFilterMask, "darned hecks", "darned hecks", true},
+		{"metacharacters quoted", ChatFilterMask, "a.b not axb", "*** not axb", true},
+		{"reject mode", ChatFilterReject, "oh darn", "", false},
+		{"reject mode clean", ChatFilterReject, "nice move", "nice move", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewChatFilter(words, tt.mode).Apply(tt.message)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Apply(%q) = %q, %v; want %q, %v", tt.message, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestChatFilterWithoutWords(t *testing.T) {
+	got, ok := NewChatFilter(nil, ChatFilterReject).Apply("anything goes")
+	if got != "anything goes" || !ok {
+		t.Errorf("Apply = %q, %v; want message through unchanged", got, ok)
+	}
+}
@@ -0,0 +1,46 @@
+package match
+
+import "context"
+
+// Arena and clan war matches are created for a pair of players before either has
+// joined. If the pair never both sit down, or the match goes away before a
+// result, the match is closed and both players are handed back to their
+// event so neither stays stuck as playing.
+
+// awaitingPair reports whether the match is still waiting for the players
+// it was created for.
+func (ms *MatchState) awaitingPair() bool {
+	return len(ms.ReservedFor) > 0 && ms.StartTime == 0 && !ms.GameOver
+}
+
+// pairingExpired reports whether the paired players had their
+// PairedSeatTimeoutSecs to join and did not both make it.
+func (ms *MatchState) pairingExpired(now int64) bool {
+	return ms.awaitingPair() && now-ms.createdAt > PairedSeatTimeoutSecs
+}
+
+// noShows returns the paired players who never took their seat.
+func (ms *MatchState) noShows() []string {
+	var missing []string
+	for _, userID := range ms.ReservedFor {
+		if _, ok := ms.Players[userID]; !ok {
+			missing = append(missing, userID)
+		}
+	}
+	return missing
+}
+
+// releasePairing closes a paired match that will not produce a result.
+// Players in idle sit out of the event; the others are paired again.
+func (s *GameService) releasePairing(ctx context.Context, state *MatchState, idle []string) {
+	if len(state.ReservedFor) == 0 {
+		return
+	}
+
+	s.logger.Info("Releasing paired players of %s", state.MatchID)
+	state.GameOver = true
+	state.closed = true
+	s.releaseArenaSeats(ctx, state, idle)
+	s.releaseClanWarSeats(ctx, state, idle)
+	s.broadcastState(state, OpCodeGameEnd)
+}
@@ -9,7 +9,13 @@ import (
 )
 
 // HandlePlayerJoin assigns a symbol and starts the game when both players are in.
-func (s *GameService) HandlePlayerJoin(state *MatchState, presence runtime.Presence, tick int64) error {
+func (s *GameService) HandlePlayerJoin(ctx context.Context, state *MatchState, presence runtime.Presence, tick int64) error {
+	if state.canRejoin(presence.GetUserId()) {
+		state.trackPresence(presence)
+		s.rejoin(state, state.Players[presence.GetUserId()])
+		return nil
+	}
+
 	if len(state.Players) >= MaxPlayers {
 		return fmt.Errorf("match is full")
 	}
@@ -19,31 +25,63 @@ func (s *GameService) HandlePlayerJoin(state *MatchState, presence runtime.Prese
 		symbol = SymbolO
 	}
 
-	state.Players[presence.GetUserId()] = &PlayerData{
+	player := &PlayerData{
 		UserID:      presence.GetUserId(),
 		Username:    presence.GetUsername(),
 		Symbol:      symbol,
 		IsConnected: true,
 	}
+	if state.botSeats[player.UserID] {
+		// Games with a bot in them are never rated for humans.
+		player.IsBot = true
+		state.Casual = true
+		s.loadBotStats(ctx, player)
+	} else {
+		s.loadPlayerStats(ctx, player)
+		s.loadCosmetics(ctx, player)
+		s.loadChatBan(ctx, player)
+	}
+	state.Players[presence.GetUserId()] = player
+	state.trackPresence(presence)
 	s.logger.Info("Player joined: %s as %s", presence.GetUsername(), symbol)
 
 	if len(state.Players) == 1 {
 		state.CurrentTurnID = presence.GetUserId()
+		state.waitingSince = time.Now().Unix()
+		if state.BotDifficulty != "" {
+			s.addBot(state, SymbolO)
+		}
 	}
 
 	if len(state.Players) == MaxPlayers {
-		now := time.Now().Unix()
-		state.StartTime = now
-		state.TurnStartTime = now
-		s.broadcastState(state, OpCodeState)
-		s.logger.Info("Match ready — starting game")
+		s.startGame(ctx, state)
 	}
 
 	return nil
 }
 
+// startGame takes any wager and starts the clock once both seats are filled.
+func (s *GameService) startGame(ctx context.Context, state *MatchState) {
+	if state.Stake > 0 {
+		if err := s.escrowWager(ctx, state); err != nil {
+			// Without both stakes there is nothing to play for.
+			s.logger.Error("Wager escrow failed in %s: %v", state.MatchID, err)
+			state.GameOver = true
+			s.broadcastState(state, OpCodeGameEnd)
+			return
+		}
+	}
+
+	now := time.Now().Unix()
+	state.StartTime = now
+	state.TurnStartTime = now
+	s.broadcastState(state, OpCodeState)
+	s.logger.Info("Match ready — starting game")
+}
+
 // HandlePlayerLeave marks a player as disconnected and awards a forfeit
-// win to the opponent if the game was still in progress.
+// win to the opponent if the game was still in progress. In a casual game
+// the seat is held for a reconnect instead, then handed to the engine.
 func (s *GameService) HandlePlayerLeave(ctx context.Context, state *MatchState, presence runtime.Presence) {
 	player, exists := state.Players[presence.GetUserId()]
 	if !exists {
@@ -51,20 +89,36 @@ func (s *GameService) HandlePlayerLeave(ctx context.Context, state *MatchState,
 	}
 
 	player.IsConnected = false
+	delete(state.presences, presence.GetUserId())
 	s.logger.Info("Player left: %s", presence.GetUsername())
 
+	if state.awaitingPair() {
+		// The pair can no longer play, so nobody is kept waiting for it.
+		s.releasePairing(ctx, state, []string{player.UserID})
+		return
+	}
+
 	if state.GameOver || len(state.Players) < MaxPlayers {
 		return
 	}
 
+	if state.canHandOver(player) {
+		player.DisconnectedAt = time.Now().Unix()
+		s.broadcastState(state, OpCodeState)
+		return
+	}
+
 	state.GameOver = true
+	state.Forfeit = true
 	for userID, p := range state.Players {
 		if p.IsConnected {
 			state.Winner = userID
-			s.updatePlayerStats(ctx, state, userID, true)
-		} else {
-			s.updatePlayerStats(ctx, state, userID, false)
+		} else if userID != player.UserID && p.awaitingReconnect() {
+			// The opponent was still in their reconnect window, so they
+			// left first and the player who stayed longer wins.
+			state.Winner = player.UserID
 		}
 	}
+	s.RecordResult(ctx, state)
 	s.broadcastState(state, OpCodeGameEnd)
 }
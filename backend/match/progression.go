@@ -0,0 +1,220 @@
+package match
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+// Quest periods.
+const (
+	QuestDaily  = "daily"
+	QuestWeekly = "weekly"
+)
+
+// Quest kinds.
+const (
+	// QuestPlay counts every finished game (forfeit losses excluded).
+	QuestPlay = "play"
+	// QuestWin counts wins.
+	QuestWin = "win"
+)
+
+// QuestDef describes one quest in the rotation pool. Mode restricts which
+// games count; empty means any mode.
+type QuestDef struct {
+	ID       string
+	Title    string
+	Period   string
+	Kind     string
+	Mode     string
+	Target   int
+	RewardXP int64
+}
+
+// QuestPool is every quest that can appear in the rotation.
+var QuestPool = []QuestDef{
+	{ID: "daily_play_3", Title: "Play 3 games", Period: QuestDaily, Kind: QuestPlay, Target: 3, RewardXP: 50},
+	{ID: "daily_play_5", Title: "Play 5 games", Period: QuestDaily, Kind: QuestPlay, Target: 5, RewardXP: 80},
+	{ID: "daily_win_2", Title: "Win 2 games", Period: QuestDaily, Kind: QuestWin, Target: 2, RewardXP: 70},
+	{ID: "daily_win_classic_2", Title: "Win 2 classic games", Period: QuestDaily, Kind: QuestWin, Mode: ModeClassic, Target: 2, RewardXP: 80},
+	{ID: "daily_win_timed_3", Title: "Win 3 timed games", Period: QuestDaily, Kind: QuestWin, Mode: ModeTimed, Target: 3, RewardXP: 120},
+	{ID: "daily_play_timed_3", Title: "Play 3 timed games", Period: QuestDaily, Kind: QuestPlay, Mode: ModeTimed, Target: 3, RewardXP: 60},
+
+	{ID: "weekly_play_20", Title: "Play 20 games", Period: QuestWeekly, Kind: QuestPlay, Target: 20, RewardXP: 300},
+	{ID: "weekly_win_10", Title: "Win 10 games", Period: QuestWeekly, Kind: QuestWin, Target: 10, RewardXP: 400},
+	{ID: "weekly_win_timed_5", Title: "Win 5 timed games", Period: QuestWeekly, Kind: QuestWin, Mode: ModeTimed, Target: 5, RewardXP: 350},
+	{ID: "weekly_play_correspondence_3", Title: "Finish 3 correspondence games", Period: QuestWeekly, Kind: QuestPlay, Mode: ModeCorrespondence, Target: 3, RewardXP: 300},
+}
+
+// How many quests of each period are active at once.
+var questsPerPeriod = map[string]int{
+	QuestDaily:  3,
+	QuestWeekly: 2,
+}
+
+// LevelThresholds is the total XP needed to reach each level, starting at
+// level 1. Past the table every level costs XPPerLevelAfterTable more.
+var LevelThresholds = []int64{0, 100, 250, 500, 900, 1400, 2000, 2800, 3800, 5000}
+
+// XPPerLevelAfterTable is the XP cost of each level beyond LevelThresholds.
+const XPPerLevelAfterTable = 1500
+
+// Base XP per result; mode bonuses are added on top.
+var (
+	xpForOutcome = map[string]int64{
+		dbpkg.OutcomeWin:  30,
+		dbpkg.OutcomeDraw: 15,
+		dbpkg.OutcomeLoss: 5,
+	}
+	xpModeBonus = map[string]int64{
+		ModeTimed:          5,
+		ModeCorrespondence: 10,
+	}
+)
+
+// ActiveQuest is a quest in the current rotation together with the period
+// it belongs to.
+type ActiveQuest struct {
+	QuestDef
+	PeriodKey string
+	ExpiresAt time.Time
+}
+
+// ActiveQuests returns the quests in rotation at t. The selection is
+// derived from the period key, so every node agrees and quests reset when
+// the period rolls over.
+func ActiveQuests(t time.Time) []ActiveQuest {
+	var active []ActiveQuest
+	for _, period := range []string{QuestDaily, QuestWeekly} {
+		key, expires := questPeriod(period, t)
+
+		var pool []QuestDef
+		for _, q := range QuestPool {
+			if q.Period == period {
+				pool = append(pool, q)
+			}
+		}
+
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		start := int(h.Sum32() % uint32(len(pool)))
+		for i := 0; i < questsPerPeriod[period] && i < len(pool); i++ {
+			active = append(active, ActiveQuest{
+				QuestDef:  pool[(start+i)%len(pool)],
+				PeriodKey: key,
+				ExpiresAt: expires,
+			})
+		}
+	}
+	return active
+}
+
+// FindActiveQuest returns the quest with the given ID if it is in the
+// current rotation.
+func FindActiveQuest(questID string, t time.Time) (ActiveQuest, bool) {
+	for _, q := range ActiveQuests(t) {
+		if q.ID == questID {
+			return q, true
+		}
+	}
+	return ActiveQuest{}, false
+}
+
+// questPeriod returns the period key for t (e.g. "2026-10-18" or
+// "2026-W42", UTC) and when that period ends.
+func questPeriod(period string, t time.Time) (string, time.Time) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	if period == QuestWeekly {
+		year, week := t.ISOWeek()
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		monday := day.AddDate(0, 0, -daysSinceMonday)
+		return fmt.Sprintf("%d-W%02d", year, week), monday.AddDate(0, 0, 7)
+	}
+	return day.Format("2006-01-02"), day.AddDate(0, 0, 1)
+}
+
+// LevelForXP returns the level reached with xp and the total XP needed for
+// the next level.
+func LevelForXP(xp int64) (level int, nextLevelXP int64) {
+	for i, threshold := range LevelThresholds {
+		if xp < threshold {
+			return i, threshold
+		}
+	}
+
+	last := LevelThresholds[len(LevelThresholds)-1]
+	extra := (xp - last) / XPPerLevelAfterTable
+	level = len(LevelThresholds) + int(extra)
+	return level, last + (extra+1)*XPPerLevelAfterTable
+}
+
+// xpForGame returns the XP earned by one player for a finished game. Wins
+// against higher-rated opponents earn a bonus; wins against much weaker
+// ones earn less.
+func xpForGame(e GameEndEvent) int64 {
+	xp := xpForOutcome[e.Outcome] + xpModeBonus[e.Mode]
+	if e.Outcome == dbpkg.OutcomeWin && e.OpponentRating > 0 && e.Rating > 0 {
+		xp += int64(min(max((e.OpponentRating-e.Rating)/20, -10), 20))
+	}
+	return max(xp, 0)
+}
+
+// questCounts reports whether the event advances the quest.
+func (q QuestDef) questCounts(e GameEndEvent) bool {
+	if q.Mode != "" && q.Mode != e.Mode {
+		return false
+	}
+	switch q.Kind {
+	case QuestPlay:
+		return !(e.Forfeit && e.Outcome == dbpkg.OutcomeLoss)
+	case QuestWin:
+		return e.Outcome == dbpkg.OutcomeWin
+	default:
+		return false
+	}
+}
+
+// updateProgression awards XP for a finished game and advances the
+// players' active quests.
+func (s *GameService) updateProgression(ctx context.Context, events []GameEndEvent) {
+	repo := dbpkg.NewRepository(s.db)
+	quests := ActiveQuests(time.Now())
+
+	for _, event := range events {
+		xp := xpForGame(event)
+		before, after, err := repo.AddXP(ctx, event.UserID, xp)
+		if err != nil {
+			s.logger.Error("XP award failed for %s: %v", event.UserID, err)
+		} else {
+			s.notifyLevelUp(ctx, event.UserID, before, after)
+		}
+
+		for _, q := range quests {
+			if !q.questCounts(event) {
+				continue
+			}
+			if err := repo.AdvanceQuest(ctx, event.UserID, q.ID, q.PeriodKey, q.Target); err != nil {
+				s.logger.Error("Quest progress failed for %s/%s: %v", event.UserID, q.ID, err)
+			}
+		}
+	}
+}
+
+func (s *GameService) notifyLevelUp(ctx context.Context, userID string, before, after int64) {
+	oldLevel, _ := LevelForXP(before)
+	newLevel, _ := LevelForXP(after)
+	if newLevel <= oldLevel {
+		return
+	}
+
+	content := map[string]interface{}{"level": newLevel, "xp": after}
+	if err := s.nk.NotificationSend(ctx, userID, "Level up", content, NotificationLevelUp, "", true); err != nil {
+		s.logger.Warn("Level-up notification to %s failed: %v", userID, err)
+	}
+}
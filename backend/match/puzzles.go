@@ -0,0 +1,275 @@
+package match
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prasanth-33460/tic-tac-toe/backend/solver"
+)
+
+// Daily puzzles are "X to win in 2" positions on larger boards or in the
+// ultimate variant. The variant rotates through puzzleSizes by day: on the
+// larger boards four in a row wins; solver.UltimateSize stands for an
+// ultimate board.
+const (
+	PuzzleMovesToWin = 2
+	puzzleWinLength  = 4
+	puzzleDateFormat = "2006-01-02"
+
+	// Puzzle variants.
+	PuzzleLarge    = "large"
+	PuzzleUltimate = "ultimate"
+
+	// puzzleDepth is enough plies to prove a win in PuzzleMovesToWin.
+	puzzleDepth = 2*PuzzleMovesToWin - 1
+
+	// puzzleMaxTries bounds the search for a position. Each variant finds
+	// one within a few dozen tries in practice.
+	puzzleMaxTries = 100000
+)
+
+var puzzleSizes = []int{4, 5, 6, solver.UltimateSize}
+
+// Puzzle is one day's puzzle. Board is row-major like the match board. In
+// ultimate puzzles NextBoard is the small board (0-8, row-major) X must
+// play in, or -1 for any open board.
+type Puzzle struct {
+	Date       string   `json:"date"`
+	Variant    string   `json:"variant"`
+	Board      []string `json:"board"`
+	Size       int      `json:"size"`
+	WinLength  int      `json:"win_length"`
+	ToMove     string   `json:"to_move"`
+	MovesToWin int      `json:"moves_to_win"`
+	NextBoard  *int     `json:"next_board,omitempty"`
+}
+
+// PuzzleMoveResult is the verdict on one submitted move. Reply is the
+// engine's defence (-1 when the puzzle is solved or the move was wrong).
+type PuzzleMoveResult struct {
+	Correct bool `json:"correct"`
+	Solved  bool `json:"solved"`
+	Reply   int  `json:"reply"`
+}
+
+var (
+	puzzleMu    sync.Mutex
+	puzzleCache *Puzzle
+)
+
+// PuzzleDate returns the puzzle date (UTC) for t.
+func PuzzleDate(t time.Time) string {
+	return t.UTC().Format(puzzleDateFormat)
+}
+
+// DailyPuzzle returns the puzzle for date (YYYY-MM-DD). Generation is
+// seeded only by the date, so every node serves the same puzzle.
+func DailyPuzzle(date string) (*Puzzle, error) {
+	puzzleMu.Lock()
+	defer puzzleMu.Unlock()
+
+	if puzzleCache != nil && puzzleCache.Date == date {
+		return puzzleCache, nil
+	}
+	day, err := time.Parse(puzzleDateFormat, date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid puzzle date: %s", date)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(date))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+	size := puzzleSizes[day.YearDay()%len(puzzleSizes)]
+
+	for try := 0; try < puzzleMaxTries; try++ {
+		puzzle := &Puzzle{
+			Date:       date,
+			Variant:    PuzzleLarge,
+			Size:       size,
+			WinLength:  puzzleWinLength,
+			ToMove:     SymbolX,
+			MovesToWin: PuzzleMovesToWin,
+		}
+		if size == solver.UltimateSize {
+			cells, next, ok := randomUltimatePosition(rng)
+			if !ok {
+				continue
+			}
+			puzzle.Variant = PuzzleUltimate
+			puzzle.WinLength = 3
+			puzzle.Board = cells
+			puzzle.NextBoard = &next
+		} else {
+			puzzle.Board = randomPuzzlePosition(rng, size)
+		}
+
+		if winner, err := puzzle.winner(puzzle.Board); err != nil {
+			return nil, err
+		} else if winner != "" {
+			continue
+		}
+		analysis, err := puzzle.analyze(puzzle.Board, puzzle.nextBoard(), puzzle.ToMove)
+		if err != nil {
+			return nil, err
+		}
+		if analysis.Outcome == solver.Win && analysis.Distance == puzzleDepth {
+			puzzleCache = puzzle
+			return puzzleCache, nil
+		}
+	}
+	return nil, fmt.Errorf("no puzzle found for %s", date)
+}
+
+// randomPuzzlePosition scatters an equal number of Xs and Os, so X is to
+// move.
+func randomPuzzlePosition(rng *rand.Rand, size int) []string {
+	cells := make([]string, size*size)
+	marks := 3 + rng.Intn(size)
+	for i := 0; i < 2*marks; i++ {
+		symbol := SymbolX
+		if i%2 == 1 {
+			symbol = SymbolO
+		}
+		for {
+			pos := rng.Intn(len(cells))
+			if cells[pos] == "" {
+				cells[pos] = symbol
+				break
+			}
+		}
+	}
+	return cells
+}
+
+// randomUltimatePosition plays an even number of random legal ultimate
+// moves from the empty board, so X is to move. It reports false if the
+// game ended on the way.
+func randomUltimatePosition(rng *rand.Rand) ([]string, int, bool) {
+	board := &solver.UltimateBoard{Cells: make([]string, solver.UltimateSize*solver.UltimateSize), Next: solver.AnyBoard}
+	symbol := SymbolX
+	for ply := 2 * (10 + rng.Intn(16)); ply > 0; ply-- {
+		moves := board.Moves()
+		if len(moves) == 0 || board.Winner() != "" {
+			return nil, 0, false
+		}
+		board.Play(moves[rng.Intn(len(moves))], symbol)
+		symbol = solver.Opponent(symbol)
+	}
+	return board.Cells, board.Next, len(board.Moves()) > 0
+}
+
+// nextBoard returns the small board X starts in, or solver.AnyBoard.
+func (p *Puzzle) nextBoard() int {
+	if p.NextBoard == nil {
+		return solver.AnyBoard
+	}
+	return *p.NextBoard
+}
+
+// Replay returns the board after moves, played alternately from X.
+func (p *Puzzle) Replay(moves []int) []string {
+	cells, _ := p.replay(moves)
+	return cells
+}
+
+// NextBoardAfter returns the small board the side to move is sent to after
+// moves in an ultimate puzzle, or nil for other variants.
+func (p *Puzzle) NextBoardAfter(moves []int) *int {
+	if p.Variant != PuzzleUltimate {
+		return nil
+	}
+	_, next := p.replay(moves)
+	return &next
+}
+
+func (p *Puzzle) replay(moves []int) ([]string, int) {
+	cells, next := slices.Clone(p.Board), p.nextBoard()
+	symbol := p.ToMove
+	for _, pos := range moves {
+		if p.Variant == PuzzleUltimate {
+			board := &solver.UltimateBoard{Cells: cells, Next: next}
+			board.Play(pos, symbol)
+			next = board.Next
+		} else {
+			cells[pos] = symbol
+		}
+		symbol = solver.Opponent(symbol)
+	}
+	return cells, next
+}
+
+// legal reports whether the side to move may play position.
+func (p *Puzzle) legal(cells []string, next, position int) bool {
+	if p.Variant == PuzzleUltimate {
+		return (&solver.UltimateBoard{Cells: cells, Next: next}).Legal(position)
+	}
+	return position >= 0 && position < len(cells) && cells[position] == ""
+}
+
+// winner returns the symbol that has won the position, if any.
+func (p *Puzzle) winner(cells []string) (string, error) {
+	if p.Variant == PuzzleUltimate {
+		return (&solver.UltimateBoard{Cells: cells}).Winner(), nil
+	}
+	board, err := solver.NewBoard(cells, p.WinLength)
+	if err != nil {
+		return "", err
+	}
+	return board.Winner(), nil
+}
+
+// analyze runs the solver for toMove under the puzzle's variant.
+func (p *Puzzle) analyze(cells []string, next int, toMove string) (*solver.Analysis, error) {
+	if p.Variant == PuzzleUltimate {
+		board, err := solver.NewUltimateBoard(cells, next)
+		if err != nil {
+			return nil, err
+		}
+		return solver.AnalyzeUltimate(board, toMove, puzzleDepth)
+	}
+	board, err := solver.NewBoard(cells, p.WinLength)
+	if err != nil {
+		return nil, err
+	}
+	return solver.Analyze(board, toMove, puzzleDepth)
+}
+
+// CheckPuzzleMove checks the solver's next move after moves. Any move
+// that still wins as fast as possible is correct; the engine then answers
+// with its longest defence.
+func CheckPuzzleMove(p *Puzzle, moves []int, position int) (*PuzzleMoveResult, error) {
+	cells, next := p.replay(moves)
+	if !p.legal(cells, next, position) {
+		return nil, fmt.Errorf("invalid position: %d", position)
+	}
+
+	analysis, err := p.analyze(cells, next, p.ToMove)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(analysis.BestMoves, position) || analysis.Outcome != solver.Win {
+		return &PuzzleMoveResult{Reply: -1}, nil
+	}
+
+	cells, next = p.replay(append(slices.Clone(moves), position))
+	winner, err := p.winner(cells)
+	if err != nil {
+		return nil, err
+	}
+	if winner == p.ToMove {
+		return &PuzzleMoveResult{Correct: true, Solved: true, Reply: -1}, nil
+	}
+
+	defence, err := p.analyze(cells, next, solver.Opponent(p.ToMove))
+	if err != nil {
+		return nil, err
+	}
+	if len(defence.BestMoves) == 0 {
+		return nil, fmt.Errorf("no defence available")
+	}
+	return &PuzzleMoveResult{Correct: true, Reply: defence.BestMoves[0]}, nil
+}
@@ -0,0 +1,198 @@
+package match
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/prasanth-33460/tic-tac-toe/backend/solver"
+)
+
+// Move labels in a game review.
+const (
+	LabelBest       = "best"
+	LabelInaccuracy = "inaccuracy"
+	LabelBlunder    = "blunder"
+)
+
+// Per-move credit towards a player's accuracy.
+var labelCredit = map[string]float64{
+	LabelBest:       1,
+	LabelInaccuracy: 0.5,
+	LabelBlunder:    0,
+}
+
+// Absolute results of a position under perfect play.
+const (
+	ResultXWins = "x_wins"
+	ResultOWins = "o_wins"
+	ResultDraw  = "draw"
+)
+
+// MoveReview is one move as judged by the solver. Before and After are the
+// mover's outcome with perfect play before and after the move; Result is
+// the absolute outcome after it.
+type MoveReview struct {
+	MoveRecord
+	Ply       int            `json:"ply"`
+	Label     string         `json:"label"`
+	BestMoves []int          `json:"best_moves"`
+	Before    solver.Outcome `json:"before"`
+	After     solver.Outcome `json:"after"`
+	Distance  int            `json:"distance,omitempty"`
+	Result    string         `json:"result"`
+}
+
+// PlayerAccuracy sums up one player's moves. A best move earns full
+// credit, an inaccuracy half and a blunder none.
+type PlayerAccuracy struct {
+	UserID       string  `json:"user_id"`
+	Symbol       string  `json:"symbol"`
+	Moves        int     `json:"moves"`
+	Best         int     `json:"best"`
+	Inaccuracies int     `json:"inaccuracies"`
+	Blunders     int     `json:"blunders"`
+	Accuracy     float64 `json:"accuracy"`
+}
+
+// GameReview is the solver's verdict on a finished game. ForcedAfter is the
+// ply after which perfect play could no longer change the actual result (0
+// means from the start), or -1 if the result was never forced,
+// e.g. a game decided by forfeit.
+type GameReview struct {
+	Moves       []MoveReview      `json:"moves"`
+	Players     []*PlayerAccuracy `json:"players"`
+	Result      string            `json:"result"`
+	ForcedAfter int               `json:"forced_after"`
+}
+
+// ReviewGame runs every recorded move through the solver under normal or
+// misère rules. winnerSymbol is "" for a draw.
+func ReviewGame(moves []MoveRecord, winnerSymbol string, misere bool) (*GameReview, error) {
+	review := &GameReview{Moves: make([]MoveReview, 0, len(moves)), Result: ResultDraw}
+	if winnerSymbol != "" {
+		review.Result = resultFor(winnerSymbol)
+	}
+	accuracy := map[string]*PlayerAccuracy{}
+
+	cells := make([]string, BoardSize)
+	// results[i] is the absolute outcome after ply i; ply 0 is the empty
+	// board, which is a draw.
+	results := []string{ResultDraw}
+
+	for i, m := range moves {
+		if m.Position < 0 || m.Position >= BoardSize || cells[m.Position] != "" {
+			return nil, fmt.Errorf("illegal move %d at ply %d", m.Position, i+1)
+		}
+
+		board, err := solver.NewBoard(append([]string(nil), cells...), 0)
+		if err != nil {
+			return nil, err
+		}
+		board.Misere = misere
+		analysis, err := solver.Analyze(board, m.Symbol, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		var played solver.Evaluation
+		for _, candidate := range analysis.Moves {
+			if candidate.Position == m.Position {
+				played = candidate.Evaluation
+			}
+		}
+
+		label := LabelBest
+		switch {
+		case outcomeRank(played.Outcome) < outcomeRank(analysis.Outcome):
+			label = LabelBlunder
+		case played.Score < analysis.Score:
+			label = LabelInaccuracy
+		}
+
+		cells[m.Position] = m.Symbol
+		result := absoluteResult(played.Outcome, m.Symbol)
+		results = append(results, result)
+
+		review.Moves = append(review.Moves, MoveReview{
+			MoveRecord: m,
+			Ply:        i + 1,
+			Label:      label,
+			BestMoves:  analysis.BestMoves,
+			Before:     analysis.Outcome,
+			After:      played.Outcome,
+			Distance:   played.Distance,
+			Result:     result,
+		})
+
+		acc, ok := accuracy[m.UserID]
+		if !ok {
+			acc = &PlayerAccuracy{UserID: m.UserID, Symbol: m.Symbol}
+			accuracy[m.UserID] = acc
+			review.Players = append(review.Players, acc)
+		}
+		acc.Moves++
+		switch label {
+		case LabelBest:
+			acc.Best++
+		case LabelInaccuracy:
+			acc.Inaccuracies++
+		case LabelBlunder:
+			acc.Blunders++
+		}
+	}
+
+	for _, acc := range review.Players {
+		credit := float64(acc.Best)*labelCredit[LabelBest] + float64(acc.Inaccuracies)*labelCredit[LabelInaccuracy]
+		acc.Accuracy = math.Round(credit/float64(acc.Moves)*1000) / 10
+	}
+
+	review.ForcedAfter = -1
+	for ply := len(results) - 1; ply >= 0 && results[ply] == review.Result; ply-- {
+		review.ForcedAfter = ply
+	}
+	return review, nil
+}
+
+// WinnerSymbol finds the winner's symbol from the move list. A winner who
+// never moved holds the other symbol.
+func WinnerSymbol(moves []MoveRecord, winnerID string) string {
+	if winnerID == "" || len(moves) == 0 {
+		return ""
+	}
+	for _, m := range moves {
+		if m.UserID == winnerID {
+			return m.Symbol
+		}
+	}
+	return solver.Opponent(moves[0].Symbol)
+}
+
+func outcomeRank(o solver.Outcome) int {
+	switch o {
+	case solver.Win:
+		return 2
+	case solver.Draw:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func resultFor(symbol string) string {
+	if symbol == SymbolX {
+		return ResultXWins
+	}
+	return ResultOWins
+}
+
+// absoluteResult turns the mover's outcome into an absolute result.
+func absoluteResult(o solver.Outcome, mover string) string {
+	switch o {
+	case solver.Win:
+		return resultFor(mover)
+	case solver.Loss:
+		return resultFor(solver.Opponent(mover))
+	default:
+		return ResultDraw
+	}
+}
@@ -8,6 +8,7 @@ import (
 )
 
 // NewGameService creates the service that all match handler methods delegate to.
+// dispatcher is nil outside a running match (see RecordCorrespondenceResult).
 func NewGameService(logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, dispatcher runtime.MatchDispatcher) *GameService {
 	return &GameService{
 		logger:     logger,
@@ -18,11 +19,26 @@ func NewGameService(logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule,
 }
 
 // broadcastState serialises the current state and sends it to all players.
+// Without a dispatcher there is no one to send it to.
 func (s *GameService) broadcastState(state *MatchState, opCode int64) {
+	if s.dispatcher == nil {
+		return
+	}
+
 	stateJSON, err := utils.JsonMarshal(state)
 	if err != nil {
 		s.logger.Error("Failed to marshal state: %v", err)
 		return
 	}
-	s.dispatcher.BroadcastMessage(opCode, stateJSON, nil, nil, true)
+
+	// External bots get their own simplified view instead.
+	humans, bots := state.splitPresences()
+	if len(bots) == 0 {
+		s.dispatcher.BroadcastMessage(opCode, stateJSON, nil, nil, true)
+		return
+	}
+	if len(humans) > 0 {
+		s.dispatcher.BroadcastMessage(opCode, stateJSON, humans, nil, true)
+	}
+	s.sendBotViews(state, opCode, bots)
 }
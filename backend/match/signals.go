@@ -3,6 +3,7 @@ package match
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -24,24 +25,63 @@ func (s *GameService) HandleSignal(ctx context.Context, state *MatchState, userI
 
 	switch signalType {
 	case "rematch_request":
-		return s.handleRematchRequest(state)
+		return s.handleRematchRequest(ctx, state)
 	case "chat_message":
 		return s.handleChatMessage(ctx, state, userID, signalData)
+	case "emote":
+		return s.handleEmote(state, userID, signalData)
+	case "mute_opponent":
+		return s.handleMuteSignal(state, userID, signalData)
+	case "block_player":
+		return s.handleBlockSignal(state, userID, signalData)
+	case "chat_ban":
+		return s.handleChatBanSignal(state, userID, signalData)
+	case "participant_check":
+		if _, ok := state.Players[userID]; !ok {
+			return "", fmt.Errorf("not a participant")
+		}
+		return "participant", nil
+	case "report_snapshot":
+		return s.handleReportSnapshot(state, userID)
 	default:
 		return "", fmt.Errorf("unknown signal type: %s", signalType)
 	}
 }
 
-func (s *GameService) handleRematchRequest(state *MatchState) (string, error) {
+func (s *GameService) handleRematchRequest(ctx context.Context, state *MatchState) (string, error) {
 	if !state.GameOver {
 		return "", fmt.Errorf("game is still in progress")
 	}
 
+	// Each arena and clan war game scores once; players are paired afresh
+	// for the next.
+	if state.ArenaID != "" || state.ClanWarID != "" {
+		return "", fmt.Errorf("arena and clan war games cannot be rematched")
+	}
+
+	// A wagered rematch puts up a fresh stake from both players.
+	if state.Stake > 0 {
+		if err := s.escrowWager(ctx, state); err != nil {
+			s.logger.Warn("Rematch escrow failed in %s: %v", state.MatchID, err)
+			return "", fmt.Errorf("both players need %d coins for a rematch", state.Stake)
+		}
+	}
+
+	state.GameIndex++
 	state.Board = [BoardSize]string{}
 	state.GameOver = false
 	state.Winner = ""
 	state.IsDraw = false
+	state.Forfeit = false
 	state.MoveCount = 0
+	state.Moves = nil
+	for _, player := range state.Players {
+		player.HintsUsed = 0
+		if !player.IsBot {
+			s.loadChatBan(ctx, player)
+		}
+	}
+	state.resetPieces()
 
 	for id := range state.Players {
 		if id != state.CurrentTurnID {
@@ -60,26 +100,45 @@ func (s *GameService) handleChatMessage(ctx context.Context, state *MatchState,
 	if !ok {
 		return "", fmt.Errorf("missing message content")
 	}
-	if len(message) == 0 || len(message) > MaxChatLength {
-		return "", fmt.Errorf("message must be between 1-%d characters", MaxChatLength)
-	}
 
 	player, ok := state.Players[userID]
 	if !ok {
 		return "", fmt.Errorf("player not found")
 	}
 
+	message, err := s.moderateChat(ctx, state, userID, message)
+	if err != nil {
+		var chatErr *ChatError
+		if errors.As(err, &chatErr) {
+			s.sendChatError(state, userID, chatErr)
+		}
+		return "", err
+	}
+
+	// The opcode and signal routes both end up here, so every message is
+	// broadcast and stored the same way.
+	record := &dbpkg.ChatMessage{
+		MatchID:   state.MatchID,
+		UserID:    userID,
+		Username:  player.Username,
+		Message:   message,
+		CreatedAt: time.Now().UTC(),
+	}
+
 	chatPayload, _ := json.Marshal(map[string]any{
 		"type":      "chat",
-		"sender":    player.Username,
-		"message":   message,
-		"timestamp": time.Now().Unix(),
+		"sender":    record.Username,
+		"message":   record.Message,
+		"timestamp": record.CreatedAt.Unix(),
 	})
 
-	s.dispatcher.BroadcastMessage(OpCodeChat, chatPayload, nil, nil, true)
+	// A non-nil empty recipient list means everyone muted the sender.
+	if recipients := state.chatRecipients(userID); recipients == nil || len(recipients) > 0 {
+		s.dispatcher.BroadcastMessage(OpCodeChat, chatPayload, recipients, nil, true)
+	}
 
 	repo := dbpkg.NewRepository(s.db)
-	if err := repo.InsertChatMessage(ctx, userID, player.Username, message); err != nil {
+	if err := repo.InsertChatMessage(ctx, record); err != nil {
 		s.logger.Error("Failed to persist chat: %v", err)
 	}
 	return "message_sent", nil
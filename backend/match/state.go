@@ -1,7 +1,10 @@
 package match
 
 import (
+	"context"
 	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
 )
 
 // NewGameState creates a blank game state for the given mode.
@@ -14,6 +17,7 @@ func NewGameState(mode string) *MatchState {
 		MoveCount:       0,
 		Metadata:        make(map[string]interface{}),
 		Preferences:     make(map[string]string),
+		createdAt:       time.Now().Unix(),
 	}
 
 	if mode == ModeTimed {
@@ -23,15 +27,64 @@ func NewGameState(mode string) *MatchState {
 	return state
 }
 
+// captureMatchID records the match ID from the handler context so it can be
+// referenced later (e.g. history, chat records).
+func (ms *MatchState) captureMatchID(ctx context.Context) {
+	if ms.MatchID != "" {
+		return
+	}
+	if matchID, ok := ctx.Value(runtime.RUNTIME_CTX_MATCH_ID).(string); ok {
+		ms.MatchID = matchID
+	}
+}
+
+// trackPresence remembers a player's presence for targeted messages.
+func (ms *MatchState) trackPresence(presence runtime.Presence) {
+	if ms.presences == nil {
+		ms.presences = make(map[string]runtime.Presence)
+	}
+	ms.presences[presence.GetUserId()] = presence
+}
+
+// Rated reports whether the game changes ratings and leaderboards. Casual
+// games count towards wins and losses only.
+func (ms *MatchState) Rated() bool {
+	return !ms.Casual
+}
+
+// Misere reports whether completing a line loses instead of wins.
+func (ms *MatchState) Misere() bool {
+	return ms.Mode == ModeMisere
+}
+
+// UsedHints reports whether either player asked for a hint this game.
+func (ms *MatchState) UsedHints() bool {
+	for _, p := range ms.Players {
+		if p.HintsUsed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // IsTimedOut returns true if the current turn has exceeded its time limit.
 func (ms *MatchState) IsTimedOut() bool {
-	if ms.Mode != ModeTimed || ms.TurnStartTime == 0 {
+	if (ms.Mode != ModeTimed && ms.Mode != ModeCorrespondence) || ms.TurnStartTime == 0 {
 		return false
 	}
 	elapsed := time.Now().Unix() - ms.TurnStartTime
 	return elapsed > int64(ms.TurnTimeoutSecs)
 }
 
+// TurnDeadline returns the unix time by which the current player must move,
+// or 0 if the mode has no per-turn limit.
+func (ms *MatchState) TurnDeadline() int64 {
+	if ms.TurnTimeoutSecs == 0 || ms.TurnStartTime == 0 {
+		return 0
+	}
+	return ms.TurnStartTime + int64(ms.TurnTimeoutSecs)
+}
+
 // SwitchTurn advances to the next player and resets the turn clock.
 func (ms *MatchState) SwitchTurn(tick int64) {
 	for userID := range ms.Players {
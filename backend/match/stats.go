@@ -2,46 +2,177 @@ package match
 
 import (
 	"context"
+	"encoding/json"
 
 	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
 )
 
-// updatePlayerStats updates the in-memory player record and writes to the Nakama leaderboards.
-func (s *GameService) updatePlayerStats(ctx context.Context, state *MatchState, userID string, won bool) {
-	player, exists := state.Players[userID]
-	if !exists {
+// updatePlayerStats records the result for every human player in one
+// transaction, refreshes the in-memory records from the stored lifetime
+// stats, and writes the winner to the Nakama leaderboards. Bots are
+// recorded separately.
+func (s *GameService) updatePlayerStats(ctx context.Context, state *MatchState) {
+	repo := dbpkg.NewRepository(s.db)
+	deltas := s.ratingDeltas(ctx, repo, state)
+
+	results := make([]dbpkg.GameResult, 0, len(state.Players))
+	for userID, player := range state.Players {
+		if player.IsBot {
+			continue
+		}
+		outcome := playerOutcome(state, userID)
+		results = append(results, dbpkg.GameResult{
+			UserID:      userID,
+			Outcome:     outcome,
+			RatingDelta: deltas[userID],
+			// Losing while still in the reconnect window means the player
+			// walked out before the engine could take over.
+			Abandoned: outcome == dbpkg.OutcomeLoss && player.awaitingReconnect(),
+		})
+	}
+
+	updated, err := repo.RecordGameResults(ctx, state.Mode, results)
+	if err != nil {
+		s.logger.Error("Failed to persist player stats: %v", err)
+	}
+
+	for userID, player := range state.Players {
+		if player.IsBot {
+			continue
+		}
+		if stats, ok := updated[userID]; ok {
+			applyPlayerStats(player, stats)
+			continue
+		}
+
+		// Persisting failed — keep the in-memory record moving so the
+		// broadcast still reflects this game.
+		switch playerOutcome(state, userID) {
+		case dbpkg.OutcomeWin:
+			player.Wins++
+			player.Streak++
+		case dbpkg.OutcomeDraw:
+			player.Draws++
+		default:
+			player.Losses++
+			player.Streak = 0
+		}
+	}
+
+	s.updateBotStats(ctx, state)
+
+	if !state.Rated() {
 		return
 	}
+	if winner, ok := state.Players[state.Winner]; ok && !winner.IsBot {
+		s.writeLeaderboardRecords(ctx, state, state.Winner, winner)
+	}
+	for userID, player := range state.Players {
+		if !player.IsBot {
+			s.writeStreakRecords(state, userID, player)
+		}
+	}
+}
 
-	if won {
-		player.Wins++
-		player.Streak++
-		s.writeLeaderboardRecords(ctx, userID, player)
-	} else if state.IsDraw {
-		player.Draws++
-	} else {
-		player.Losses++
-		player.Streak = 0
+// loadPlayerStats fills in a player's lifetime stats from the database.
+func (s *GameService) loadPlayerStats(ctx context.Context, player *PlayerData) {
+	stats, err := dbpkg.NewRepository(s.db).GetPlayerStats(ctx, player.UserID)
+	if err != nil {
+		s.logger.Warn("Failed to load stats for %s: %v", player.UserID, err)
+		player.Rating = dbpkg.DefaultSkillRating
+		return
 	}
+	applyPlayerStats(player, stats)
 }
 
-func (s *GameService) writeLeaderboardRecords(ctx context.Context, userID string, player *PlayerData) {
-	if err := dbpkg.EnsureLeaderboards(s.logger, s.nk); err != nil {
-		s.logger.Warn("Leaderboard ensure failed: %v", err)
+func applyPlayerStats(player *PlayerData, stats *dbpkg.PlayerStats) {
+	player.Wins = stats.Wins
+	player.Losses = stats.Losses
+	player.Draws = stats.Draws
+	player.Streak = stats.CurrentStreak
+	player.Rating = stats.SkillRating
+}
+
+// playerOutcome returns the finished game's result from userID's side.
+func playerOutcome(state *MatchState, userID string) string {
+	switch {
+	case state.IsDraw:
+		return dbpkg.OutcomeDraw
+	case state.Winner == userID:
+		return dbpkg.OutcomeWin
+	default:
+		return dbpkg.OutcomeLoss
 	}
+}
 
+// ratingDeltas returns each player's Elo rating change for the result,
+// based on their current stored ratings. Casual games change nothing.
+// Arena pairing already matched on player_stats.skill_rating but nothing
+// ever moved it, so it is updated here, in the same transaction as the
+// totals and streak.
+func (s *GameService) ratingDeltas(ctx context.Context, repo *dbpkg.Repository, state *MatchState) map[string]int {
+	deltas := make(map[string]int, len(state.Players))
+	if len(state.Players) != MaxPlayers || !state.Rated() {
+		return deltas
+	}
+
+	var ids []string
+	ratings := make(map[string]int, MaxPlayers)
+	for userID := range state.Players {
+		rating, err := repo.GetSkillRating(ctx, userID)
+		if err != nil {
+			s.logger.Warn("Failed to load rating for %s: %v", userID, err)
+			rating = dbpkg.DefaultSkillRating
+		}
+		ids = append(ids, userID)
+		ratings[userID] = rating
+	}
+
+	a, b := ids[0], ids[1]
+	delta := eloDelta(ratings[a], ratings[b], playerOutcome(state, a))
+	deltas[a] = delta
+	deltas[b] = -delta
+	return deltas
+}
+
+// writeLeaderboardRecords writes a win to the overall boards, to the
+// all-time and seasonal boards for the match's mode, and to the winner's
+// clan.
+func (s *GameService) writeLeaderboardRecords(ctx context.Context, state *MatchState, userID string, player *PlayerData) {
 	serverCtx := context.Background()
 
-	if _, err := s.nk.LeaderboardRecordWrite(serverCtx, LeaderboardGlobalWins, userID, player.Username, 1, 0, nil, nil); err != nil {
-		s.logger.Error("Wins leaderboard write failed for %s: %v", userID, err)
+	for _, id := range leaderboardIDs(LeaderboardGlobalWins, state.Mode) {
+		if _, err := s.nk.LeaderboardRecordWrite(serverCtx, id, userID, player.Username, 1, 0, nil, nil); err != nil {
+			s.logger.Error("Wins leaderboard %s write failed for %s: %v", id, userID, err)
+		}
 	}
 
-	if _, err := s.nk.LeaderboardRecordWrite(serverCtx, LeaderboardWinStreaks, userID, player.Username, int64(player.Streak), 0, nil, nil); err != nil {
-		s.logger.Error("Streak leaderboard write failed for %s: %v", userID, err)
+	s.writeClanWin(ctx, userID)
+}
+
+// writeStreakRecords sets the player's current win streak on the streak
+// boards after every rated result, so a loss shows as 0 rather than
+// leaving the old streak up.
+func (s *GameService) writeStreakRecords(state *MatchState, userID string, player *PlayerData) {
+	for _, id := range leaderboardIDs(LeaderboardWinStreaks, state.Mode) {
+		if _, err := s.nk.LeaderboardRecordWrite(context.Background(), id, userID, player.Username, int64(player.Streak), 0, nil, nil); err != nil {
+			s.logger.Error("Streak leaderboard %s write failed for %s: %v", id, userID, err)
+		}
 	}
 }
 
-// recordMatchHistory persists the match outcome to the database.
+// leaderboardIDs returns every board a result in mode counts towards.
+func leaderboardIDs(base, mode string) []string {
+	ids := []string{base}
+	if utils.ContainsString(dbpkg.LeaderboardModes, mode) {
+		ids = append(ids, dbpkg.ModeLeaderboardID(base, mode), dbpkg.SeasonLeaderboardID(base, mode))
+	}
+	return ids
+}
+
+// recordMatchHistory persists the match outcome to the database along with
+// the move list and the solver's review of the game.
 func (s *GameService) recordMatchHistory(ctx context.Context, state *MatchState) {
 	if state.Winner == "" && !state.IsDraw {
 		return
@@ -64,8 +195,42 @@ func (s *GameService) recordMatchHistory(ctx context.Context, state *MatchState)
 		s.logger.Warn("MatchID missing, using generated key: %s", matchID)
 	}
 
+	playerIDs := make([]string, 0, len(state.Players))
+	for id := range state.Players {
+		playerIDs = append(playerIDs, id)
+	}
+	players, _ := json.Marshal(playerIDs)
+	moves, _ := json.Marshal(state.Moves)
+
+	var review []byte
+	winnerSymbol := ""
+	if winner, ok := state.Players[state.Winner]; ok {
+		winnerSymbol = winner.Symbol
+	}
+	// The solver knows nothing of vanishing marks, so those games go
+	// unreviewed.
+	if !state.Vanishing() {
+		if gameReview, err := ReviewGame(state.Moves, winnerSymbol, state.Misere()); err != nil {
+			s.logger.Warn("Game review failed for %s: %v", matchID, err)
+		} else {
+			review, _ = json.Marshal(gameReview)
+		}
+	}
+
 	repo := dbpkg.NewRepository(s.db)
-	if err := repo.RecordMatchResult(ctx, matchID, state.Winner, loserID, state.Mode, state.StartTime); err != nil {
+	rec := &dbpkg.MatchRecord{
+		MatchID:   matchID,
+		GameIndex: state.GameIndex,
+		WinnerID:  state.Winner,
+		LoserID:   loserID,
+		Mode:      state.Mode,
+		StartTime: state.StartTime,
+		Players:   players,
+		Moves:     moves,
+		Review:    review,
+		UsedHints: state.UsedHints(),
+	}
+	if err := repo.RecordMatchResult(ctx, rec); err != nil {
 		s.logger.Error("Failed to record match history: %v", err)
 	}
 }
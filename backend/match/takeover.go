@@ -0,0 +1,92 @@
+package match
+
+import (
+	"context"
+	"time"
+
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+// In casual games a player who disconnects mid-game has ReconnectWindowSecs
+// to come back. After that the engine takes over their seat so the other
+// player can finish, and the leaver is charged an abandoned loss straight
+// away. Rated games are forfeited on leaving as before.
+
+// canHandOver reports whether leaver's seat may wait for a reconnect and
+// then go to the engine rather than being forfeited. The engine plays with
+// the solver, which knows nothing of vanishing marks, so vanishing games
+// are forfeited too.
+func (ms *MatchState) canHandOver(leaver *PlayerData) bool {
+	if ms.Rated() || ms.Vanishing() || leaver.IsBot {
+		return false
+	}
+	for userID, p := range ms.Players {
+		if userID != leaver.UserID && (!p.IsConnected || p.IsBot) {
+			return false
+		}
+	}
+	return true
+}
+
+// awaitingReconnect reports whether the player left a casual game and is
+// still within their reconnect window.
+func (p *PlayerData) awaitingReconnect() bool {
+	return !p.IsConnected && p.DisconnectedAt > 0 && !p.TakenOver
+}
+
+// canRejoin reports whether userID is a disconnected player whose seat is
+// still waiting for them.
+func (ms *MatchState) canRejoin(userID string) bool {
+	player, ok := ms.Players[userID]
+	return ok && !ms.GameOver && player.awaitingReconnect()
+}
+
+// rejoin puts a reconnecting player back in their seat.
+func (s *GameService) rejoin(state *MatchState, player *PlayerData) {
+	player.IsConnected = true
+	player.DisconnectedAt = 0
+	s.logger.Info("Player rejoined: %s", player.Username)
+	s.broadcastState(state, OpCodeState)
+}
+
+// takeOverAbandonedSeats hands every seat whose reconnect window has run
+// out to the engine, at a difficulty matched to the leaver's rating.
+func (s *GameService) takeOverAbandonedSeats(ctx context.Context, state *MatchState) {
+	if state.GameOver {
+		return
+	}
+	now := time.Now().Unix()
+	for _, player := range state.Players {
+		if player.IsConnected || player.TakenOver || player.DisconnectedAt == 0 {
+			continue
+		}
+		if now-player.DisconnectedAt < ReconnectWindowSecs {
+			continue
+		}
+
+		s.recordAbandonment(ctx, state, player)
+		player.TakenOver = true
+		player.IsBot = true
+		player.BotDifficulty = BotDifficultyForRating(player.Rating)
+		player.IsConnected = true
+		s.logger.Info("Engine (%s) took over %s's seat in %s", player.BotDifficulty, player.Username, state.MatchID)
+		s.broadcastState(state, OpCodeState)
+	}
+}
+
+// recordAbandonment charges the leaver a loss and an abandonment. The
+// engine's result in their seat is not theirs and is never recorded.
+func (s *GameService) recordAbandonment(ctx context.Context, state *MatchState, player *PlayerData) {
+	updated, err := dbpkg.NewRepository(s.db).RecordGameResults(ctx, state.Mode, []dbpkg.GameResult{{
+		UserID:    player.UserID,
+		Outcome:   dbpkg.OutcomeLoss,
+		Abandoned: true,
+	}})
+	if err != nil {
+		s.logger.Error("Failed to record abandonment for %s: %v", player.UserID, err)
+		return
+	}
+	if stats, ok := updated[player.UserID]; ok {
+		applyPlayerStats(player, stats)
+	}
+}
@@ -14,12 +14,14 @@ type Match struct {
 // MatchState holds the full state of a single tic-tac-toe game.
 type MatchState struct {
 	MatchID         string                 `json:"match_id"`
+	GameIndex       int                    `json:"game_index"`
 	Board           [BoardSize]string      `json:"board"`
 	Players         map[string]*PlayerData `json:"players"`
 	CurrentTurnID   string                 `json:"current_turn_id"`
 	Winner          string                 `json:"winner"`
 	GameOver        bool                   `json:"game_over"`
 	IsDraw          bool                   `json:"is_draw"`
+	Forfeit         bool                   `json:"forfeit,omitempty"`
 	Mode            string                 `json:"mode"`
 	StartTime       int64                  `json:"start_time"`
 	TurnStartTime   int64                  `json:"turn_start_time"`
@@ -27,18 +29,74 @@ type MatchState struct {
 	MoveCount       int                    `json:"move_count"`
 	Metadata        map[string]interface{} `json:"metadata"`
 	Preferences     map[string]string      `json:"preferences"`
+	ArenaID         string                 `json:"arena_id,omitempty"`
+	ReservedFor     []string               `json:"reserved_for,omitempty"`
+	Stake           int64                  `json:"stake,omitempty"`
+	EscrowID        string                 `json:"escrow_id,omitempty"`
+	ClanWarID       string                 `json:"clan_war_id,omitempty"`
+	Moves           []MoveRecord           `json:"moves"`
+	EmotesOnly      bool                   `json:"emotes_only,omitempty"`
+	Casual          bool                   `json:"casual,omitempty"`
+	BotDifficulty   string                 `json:"bot_difficulty,omitempty"`
+	BotFillSecs     int                    `json:"bot_fill_secs,omitempty"`
+	BotOffered      bool                   `json:"bot_offered,omitempty"`
+	VanishNext      map[string]int         `json:"vanish_next,omitempty"`
+	DrawReason      string                 `json:"draw_reason,omitempty"`
+
+	// Live-match bookkeeping, never broadcast or persisted.
+	presences       map[string]runtime.Presence
+	chatTimes       map[string][]int64
+	emoteTimes      map[string][]int64
+	botMessageTimes map[string][]int64
+	mutes           map[string]map[string]bool
+	botSeats        map[string]bool
+	waitingSince    int64
+	createdAt       int64
+	closed          bool
+	positionCounts  map[string]int
 }
 
-// PlayerData tracks per-player info within a match.
+// PlayerData tracks per-player info within a match. Wins, Losses, Draws,
+// Streak and Rating are the player's lifetime stats, loaded on join.
+// MarkerSkin, BoardTheme and WinAnimation are the equipped cosmetics; they
+// only change how Symbol is drawn. IsBot marks a seat not played by a
+// human; BotDifficulty is set when the server itself plays it and empty
+// for a registered external bot, whose stats are its bot ladder record.
+// TakenOver marks a human's seat the engine took over after they left a
+// casual game; DisconnectedAt is when they left. Pieces are the player's
+// marks on the board in a vanishing game, oldest first. chatBanned is the
+// player's chat ban, loaded on join and updated when a moderator acts.
 type PlayerData struct {
-	UserID      string `json:"user_id"`
-	Username    string `json:"username"`
-	Symbol      string `json:"symbol"`
-	IsConnected bool   `json:"is_connected"`
-	Wins        int    `json:"wins"`
-	Losses      int    `json:"losses"`
-	Draws       int    `json:"draws"`
-	Streak      int    `json:"streak"`
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	Symbol         string `json:"symbol"`
+	IsConnected    bool   `json:"is_connected"`
+	Wins           int    `json:"wins"`
+	Losses         int    `json:"losses"`
+	Draws          int    `json:"draws"`
+	Streak         int    `json:"streak"`
+	Rating         int    `json:"rating"`
+	MarkerSkin     string `json:"marker_skin,omitempty"`
+	BoardTheme     string `json:"board_theme,omitempty"`
+	WinAnimation   string `json:"win_animation,omitempty"`
+	HintsUsed      int    `json:"hints_used,omitempty"`
+	IsBot          bool   `json:"is_bot,omitempty"`
+	DisconnectedAt int64  `json:"disconnected_at,omitempty"`
+	TakenOver      bool   `json:"taken_over,omitempty"`
+	Pieces         []int  `json:"pieces,omitempty"`
+	BotDifficulty  string `json:"bot_difficulty,omitempty"`
+
+	chatBanned bool
+}
+
+// MoveRecord is one move as played, in order. Vanished is the mark the
+// move removed in a vanishing game.
+type MoveRecord struct {
+	UserID    string `json:"user_id"`
+	Symbol    string `json:"symbol"`
+	Position  int    `json:"position"`
+	Timestamp int64  `json:"timestamp"`
+	Vanished  *int   `json:"vanished,omitempty"`
 }
 
 // MoveMessage is the payload sent by a client when making a move.
@@ -59,5 +117,3 @@ type ValidationResult struct {
 	Valid   bool   `json:"valid"`
 	Message string `json:"message,omitempty"`
 }
-
-
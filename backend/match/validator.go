@@ -13,14 +13,27 @@ import (
 // Join validation
 // ---------------------------------------------------------------------------
 
-// ValidateJoinRequest checks bans, skill compatibility, and mode before
-// allowing a player into the match.
+// ValidateJoinRequest checks bans, the bot flag, blocks, reserved seats,
+// skill compatibility, mode and stake before allowing a player into the
+// match.
 func (s *GameService) ValidateJoinRequest(ctx context.Context, state *MatchState, userID string, metadata map[string]string) ValidationResult {
 	repo := dbpkg.NewRepository(s.db)
 	if banned, err := repo.IsPlayerBanned(ctx, userID); err == nil && banned {
 		return ValidationResult{Valid: false, Message: "player is banned"}
 	}
 
+	if result := s.validateBotSeat(ctx, state, userID, metadata); !result.Valid {
+		return result
+	}
+
+	if result := s.validateBlocks(ctx, state, userID); !result.Valid {
+		return result
+	}
+
+	if result := validateReservedSeat(state.ReservedFor, userID); !result.Valid {
+		return result
+	}
+
 	playerSkill, matchSkill := getSkillLevels(metadata, state.Metadata)
 	if result := validateSkillCompatibility(playerSkill, matchSkill); !result.Valid {
 		return result
@@ -30,6 +43,10 @@ func (s *GameService) ValidateJoinRequest(ctx context.Context, state *MatchState
 		return result
 	}
 
+	if result := s.validateStake(ctx, state.Stake, userID, metadata); !result.Valid {
+		return result
+	}
+
 	return ValidationResult{Valid: true}
 }
 
@@ -60,6 +77,56 @@ func validateSkillCompatibility(playerSkill, matchSkill int) ValidationResult {
 	return ValidationResult{Valid: true}
 }
 
+// validateReservedSeat only lets the intended players into matches created
+// for a specific pair (e.g. arena pairings).
+func validateReservedSeat(reservedFor []string, userID string) ValidationResult {
+	if len(reservedFor) == 0 || utils.ContainsString(reservedFor, userID) {
+		return ValidationResult{Valid: true}
+	}
+	return ValidationResult{Valid: false, Message: "seat is reserved"}
+}
+
+// validateBlocks keeps a player out of a match with anyone they have
+// blocked or who has blocked them.
+func (s *GameService) validateBlocks(ctx context.Context, state *MatchState, userID string) ValidationResult {
+	repo := dbpkg.NewRepository(s.db)
+	for playerID := range state.Players {
+		if playerID == userID {
+			continue
+		}
+		blocked, err := repo.IsBlockedEither(ctx, userID, playerID)
+		if err != nil {
+			s.logger.Error("Block lookup failed for %s/%s: %v", userID, playerID, err)
+			return ValidationResult{Valid: false, Message: "block list unavailable"}
+		}
+		if blocked {
+			return ValidationResult{Valid: false, Message: "player is blocked"}
+		}
+	}
+	return ValidationResult{Valid: true}
+}
+
+// validateStake requires players joining a wagered match to agree to the
+// stake and to be able to cover it.
+func (s *GameService) validateStake(ctx context.Context, stake int64, userID string, metadata map[string]string) ValidationResult {
+	if stake == 0 {
+		return ValidationResult{Valid: true}
+	}
+	if metadata["stake"] != strconv.FormatInt(stake, 10) {
+		return ValidationResult{Valid: false, Message: fmt.Sprintf("match stake is %d coins", stake)}
+	}
+
+	balance, err := WalletBalance(ctx, s.nk, userID)
+	if err != nil {
+		s.logger.Error("Wallet lookup failed for %s: %v", userID, err)
+		return ValidationResult{Valid: false, Message: "wallet unavailable"}
+	}
+	if balance < stake {
+		return ValidationResult{Valid: false, Message: "insufficient coins"}
+	}
+	return ValidationResult{Valid: true}
+}
+
 func validateGameMode(playerMode, gameMode string) ValidationResult {
 	if playerMode == "" || playerMode == gameMode {
 		return ValidationResult{Valid: true}
@@ -97,19 +164,25 @@ func ValidateMove(state *MatchState, userID string, position int) error {
 }
 
 // CheckWinner scans the board for a three-in-a-row or a full board draw.
+// In misère games the player who completed the line loses; vanishing games
+// never fill the board and are drawn by repetition or the move cap.
 func CheckWinner(state *MatchState) (winner string, isDraw bool) {
 	for _, pattern := range WinPatterns {
 		a, b, c := state.Board[pattern[0]], state.Board[pattern[1]], state.Board[pattern[2]]
 
 		if a != "" && a == b && b == c {
 			for userID, player := range state.Players {
-				if player.Symbol == a {
+				if (player.Symbol == a) != state.Misere() {
 					return userID, false
 				}
 			}
 		}
 	}
 
+	if state.Vanishing() {
+		return "", state.vanishingDraw() != ""
+	}
+
 	if state.MoveCount >= BoardSize {
 		return "", true
 	}
@@ -0,0 +1,104 @@
+package match
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prasanth-33460/tic-tac-toe/backend/solver"
+)
+
+// In vanishing games each player keeps at most VanishingMaxPieces marks:
+// placing another removes their oldest first. The board never fills, so a
+// game is drawn when a position repeats VanishingRepetitions times or after
+// VanishingMoveCap moves.
+
+// Reasons a vanishing game was drawn.
+const (
+	DrawRepetition = "repetition"
+	DrawMoveCap    = "move_cap"
+)
+
+// Vanishing reports whether each player's oldest mark vanishes once they
+// have VanishingMaxPieces on the board.
+func (ms *MatchState) Vanishing() bool {
+	return ms.Mode == ModeVanishing
+}
+
+// vanishOldest removes the player's oldest mark if placing another would
+// take them over the limit. Returns the cleared position.
+func (ms *MatchState) vanishOldest(player *PlayerData) (int, bool) {
+	if len(player.Pieces) < VanishingMaxPieces {
+		return 0, false
+	}
+	oldest := player.Pieces[0]
+	player.Pieces = player.Pieces[1:]
+	ms.Board[oldest] = ""
+	return oldest, true
+}
+
+// trackPiece adds a newly placed mark to the player's queue, counts the
+// resulting position and refreshes VanishNext.
+func (ms *MatchState) trackPiece(player *PlayerData, position int) {
+	player.Pieces = append(player.Pieces, position)
+
+	if ms.positionCounts == nil {
+		ms.positionCounts = make(map[string]int)
+	}
+	ms.positionCounts[ms.positionKey(solver.Opponent(player.Symbol))]++
+
+	ms.VanishNext = make(map[string]int)
+	for userID, p := range ms.Players {
+		if len(p.Pieces) >= VanishingMaxPieces {
+			ms.VanishNext[userID] = p.Pieces[0]
+		}
+	}
+}
+
+// positionKey identifies the position for repetition: the board, the side
+// to move and both piece queues, since the queues decide what vanishes
+// next.
+func (ms *MatchState) positionKey(toMove string) string {
+	var b strings.Builder
+	for _, cell := range ms.Board {
+		if cell == "" {
+			cell = "-"
+		}
+		b.WriteString(cell)
+	}
+	b.WriteString("|" + toMove)
+	for _, symbol := range []string{SymbolX, SymbolO} {
+		b.WriteString("|")
+		for _, p := range ms.Players {
+			if p.Symbol != symbol {
+				continue
+			}
+			for _, pos := range p.Pieces {
+				b.WriteString(strconv.Itoa(pos))
+			}
+		}
+	}
+	return b.String()
+}
+
+// vanishingDraw returns why the game is drawn, or "" if it is not.
+func (ms *MatchState) vanishingDraw() string {
+	if ms.MoveCount >= VanishingMoveCap {
+		return DrawMoveCap
+	}
+	for _, count := range ms.positionCounts {
+		if count >= VanishingRepetitions {
+			return DrawRepetition
+		}
+	}
+	return ""
+}
+
+// resetPieces clears the piece queues for a new game.
+func (ms *MatchState) resetPieces() {
+	for _, p := range ms.Players {
+		p.Pieces = nil
+	}
+	ms.VanishNext = nil
+	ms.DrawReason = ""
+	ms.positionCounts = nil
+}
@@ -0,0 +1,134 @@
+package match
+
+import (
+	"slices"
+	"testing"
+)
+
+// newTwoPlayerState seats "x" as X and "o" as O in a fresh game of mode.
+func newTwoPlayerState(mode string) *MatchState {
+	state := NewGameState(mode)
+	state.Players["x"] = &PlayerData{UserID: "x", Symbol: SymbolX}
+	state.Players["o"] = &PlayerData{UserID: "o", Symbol: SymbolO}
+	return state
+}
+
+// playAlternating places marks at positions, X first, and returns the
+// outcome of the last move.
+func playAlternating(state *MatchState, positions []int) (winner string, isDraw bool) {
+	for i, pos := range positions {
+		player := state.Players["x"]
+		if i%2 == 1 {
+			player = state.Players["o"]
+		}
+		winner, isDraw = placeMark(state, player, pos)
+	}
+	return winner, isDraw
+}
+
+func TestPlaceMarkVanishing(t *testing.T) {
+	tests := []struct {
+		name     string
+		moves    []int
+		xPieces  []int
+		vanished *int
+		winner   string
+	}{
+		{"three marks stay", []int{0, 4, 1, 6, 5}, []int{0, 1, 5}, nil, ""},
+		{"fourth mark removes the oldest", []int{0, 4, 1, 6, 5, 8, 7}, []int{1, 5, 7}, intPtr(0), ""},
+		{"vanished mark breaks the line", []int{0, 4, 1, 8, 5, 3, 2}, []int{1, 5, 2}, intPtr(0), ""},
+		{"line with the new mark wins", []int{0, 4, 1, 8, 2}, []int{0, 1, 2}, nil, "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTwoPlayerState(ModeVanishing)
+			winner, isDraw := playAlternating(state, tt.moves)
+
+			if winner != tt.winner || isDraw {
+				t.Errorf("outcome = %q, draw %v; want %q, no draw", winner, isDraw, tt.winner)
+			}
+			if got := state.Players["x"].Pieces; !slices.Equal(got, tt.xPieces) {
+				t.Errorf("X pieces = %v, want %v", got, tt.xPieces)
+			}
+			last := state.Moves[len(state.Moves)-1]
+			if (last.Vanished == nil) != (tt.vanished == nil) || (last.Vanished != nil && *last.Vanished != *tt.vanished) {
+				t.Errorf("last move vanished %v, want %v", last.Vanished, tt.vanished)
+			}
+			if tt.vanished != nil && state.Board[*tt.vanished] != "" {
+				t.Errorf("vanished cell %d still holds %q", *tt.vanished, state.Board[*tt.vanished])
+			}
+			if len(state.Moves) != len(tt.moves) || state.MoveCount != len(tt.moves) {
+				t.Errorf("recorded %d moves, count %d; want %d", len(state.Moves), state.MoveCount, len(tt.moves))
+			}
+		})
+	}
+}
+
+func TestPlaceMarkVanishNext(t *testing.T) {
+	state := newTwoPlayerState(ModeVanishing)
+	playAlternating(state, []int{0, 4, 1, 6, 5})
+
+	if next, ok := state.VanishNext["x"]; !ok || next != 0 {
+		t.Errorf("VanishNext[x] = %d, %v; want 0", next, ok)
+	}
+	if _, ok := state.VanishNext["o"]; ok {
+		t.Errorf("O has two marks but is listed in VanishNext")
+	}
+}
+
+func TestPlaceMarkVanishingRepetitionDraw(t *testing.T) {
+	// Each side cycles its marks round four cells that hold no line, so
+	// the position X[0 1 5] O[2 3 7] with X to move comes back every
+	// eight moves once both queues are full.
+	cycle := []int{0, 2, 1, 3, 5, 7, 6, 8}
+
+	state := newTwoPlayerState(ModeVanishing)
+	for ply := 0; ply < 40; ply++ {
+		player := state.Players["x"]
+		if ply%2 == 1 {
+			player = state.Players["o"]
+		}
+		winner, isDraw := placeMark(state, player, cycle[ply%len(cycle)])
+		if winner != "" {
+			t.Fatalf("ply %d: unexpected winner %s", ply+1, winner)
+		}
+		if isDraw {
+			if ply+1 != 22 || state.DrawReason != DrawRepetition {
+				t.Errorf("drawn at ply %d for %q, want ply 22 for %q", ply+1, state.DrawReason, DrawRepetition)
+			}
+			return
+		}
+	}
+	t.Fatalf("no repetition draw after 40 plies")
+}
+
+func TestPlaceMarkVanishingMoveCap(t *testing.T) {
+	state := newTwoPlayerState(ModeVanishing)
+	state.MoveCount = VanishingMoveCap - 1
+
+	winner, isDraw := placeMark(state, state.Players["x"], 4)
+	if winner != "" || !isDraw || state.DrawReason != DrawMoveCap {
+		t.Errorf("got winner %q, draw %v, reason %q; want a %q draw", winner, isDraw, state.DrawReason, DrawMoveCap)
+	}
+}
+
+func TestPlaceMarkClassicKeepsEveryMark(t *testing.T) {
+	state := newTwoPlayerState(ModeClassic)
+	playAlternating(state, []int{0, 4, 1, 6, 5, 8, 7})
+
+	if state.Board[0] != SymbolX {
+		t.Errorf("classic board lost X's first mark")
+	}
+	for _, m := range state.Moves {
+		if m.Vanished != nil {
+			t.Errorf("classic move at %d vanished %d", m.Position, *m.Vanished)
+		}
+	}
+	if len(state.Players["x"].Pieces) != 0 {
+		t.Errorf("classic game tracked pieces %v", state.Players["x"].Pieces)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
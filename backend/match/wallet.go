@@ -0,0 +1,280 @@
+package match
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
+)
+
+// Ledger reasons attached to every wallet movement as metadata.
+const (
+	LedgerWinReward   = "win_reward"
+	LedgerDailyLogin  = "daily_login"
+	LedgerWagerEscrow = "wager_escrow"
+	LedgerWagerPayout = "wager_payout"
+	LedgerWagerRefund = "wager_refund"
+)
+
+// escrowLedgerScanLimit caps how many ledger entries recovery inspects per
+// player when reconstructing what happened to an escrow.
+const escrowLedgerScanLimit = 500
+
+// WalletBalance returns the player's coin balance.
+func WalletBalance(ctx context.Context, nk runtime.NakamaModule, userID string) (int64, error) {
+	account, err := nk.AccountGetId(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	wallet := map[string]int64{}
+	if raw := account.GetWallet(); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &wallet); err != nil {
+			return 0, err
+		}
+	}
+	return wallet[WalletCurrency], nil
+}
+
+// CreditCoins adds coins to a player's wallet with a ledger entry.
+func CreditCoins(ctx context.Context, nk runtime.NakamaModule, userID string, coins int64, metadata map[string]interface{}) error {
+	_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{WalletCurrency: coins}, metadata, true)
+	return err
+}
+
+// awardWinCoins pays the winner of a finished rated game against a human.
+// Casual games and wins over a bot or an engine-played seat pay nothing,
+// so coins cannot be farmed off the engine. Bots have no wallet.
+func (s *GameService) awardWinCoins(ctx context.Context, state *MatchState) {
+	if winner, ok := state.Players[state.Winner]; !ok || winner.IsBot || !state.Rated() {
+		return
+	}
+	for userID, opponent := range state.Players {
+		if userID != state.Winner && (opponent.IsBot || opponent.TakenOver) {
+			return
+		}
+	}
+
+	metadata := map[string]interface{}{"reason": LedgerWinReward, "match_id": state.MatchID}
+	if err := CreditCoins(ctx, s.nk, state.Winner, CoinsPerWin, metadata); err != nil {
+		s.logger.Error("Win reward failed for %s: %v", state.Winner, err)
+	}
+}
+
+// escrowWager takes the agreed stake from both players before the game
+// starts. The escrow row is written first so that a crash between the
+// database write and the wallet debit can be recovered from.
+func (s *GameService) escrowWager(ctx context.Context, state *MatchState) error {
+	var userIDs []string
+	for userID := range state.Players {
+		userIDs = append(userIDs, userID)
+	}
+	if len(userIDs) != MaxPlayers {
+		return fmt.Errorf("wager needs %d players", MaxPlayers)
+	}
+
+	escrow := &dbpkg.WagerEscrow{
+		EscrowID: utils.NewID("esc"),
+		MatchID:  state.MatchID,
+		PlayerA:  userIDs[0],
+		PlayerB:  userIDs[1],
+		Stake:    state.Stake,
+	}
+	repo := dbpkg.NewRepository(s.db)
+	if err := repo.CreateEscrow(ctx, escrow); err != nil {
+		return fmt.Errorf("create escrow: %w", err)
+	}
+
+	metadata := escrowMetadata(LedgerWagerEscrow, escrow)
+	updates := make([]*runtime.WalletUpdate, 0, len(userIDs))
+	for _, userID := range userIDs {
+		updates = append(updates, &runtime.WalletUpdate{
+			UserID:    userID,
+			Changeset: map[string]int64{WalletCurrency: -escrow.Stake},
+			Metadata:  metadata,
+		})
+	}
+	if _, err := s.nk.WalletsUpdate(ctx, updates, true); err != nil {
+		if _, cerr := repo.UpdateEscrowStatus(ctx, escrow.EscrowID, dbpkg.EscrowPending, dbpkg.EscrowCancelled, ""); cerr != nil {
+			s.logger.Error("Escrow %s cancel failed: %v", escrow.EscrowID, cerr)
+		}
+		return fmt.Errorf("debit stakes: %w", err)
+	}
+
+	if _, err := repo.UpdateEscrowStatus(ctx, escrow.EscrowID, dbpkg.EscrowPending, dbpkg.EscrowHeld, ""); err != nil {
+		// The stakes are debited; startup recovery finds the ledger entries
+		// and treats the escrow as held.
+		s.logger.Error("Escrow %s hold failed: %v", escrow.EscrowID, err)
+	}
+
+	state.EscrowID = escrow.EscrowID
+	s.logger.Info("Escrowed %d coins from each player in %s", escrow.Stake, state.MatchID)
+	return nil
+}
+
+// settleWager pays out the pot to the winner, or refunds both stakes on a
+// draw.
+func (s *GameService) settleWager(ctx context.Context, state *MatchState) {
+	if state.EscrowID == "" {
+		return
+	}
+
+	escrow := s.stateEscrow(state)
+	if err := settleEscrow(ctx, s.db, s.nk, escrow, state.Winner); err != nil {
+		s.logger.Error("Escrow %s settlement failed: %v", escrow.EscrowID, err)
+		return
+	}
+	state.EscrowID = ""
+}
+
+// refundWager returns both stakes of a game that was abandoned before it
+// finished.
+func (s *GameService) refundWager(ctx context.Context, state *MatchState) {
+	if state.EscrowID == "" {
+		return
+	}
+
+	escrow := s.stateEscrow(state)
+	if err := settleEscrow(ctx, s.db, s.nk, escrow, ""); err != nil {
+		s.logger.Error("Escrow %s refund failed: %v", escrow.EscrowID, err)
+		return
+	}
+	state.EscrowID = ""
+	s.logger.Info("Refunded abandoned wager in %s", state.MatchID)
+}
+
+func (s *GameService) stateEscrow(state *MatchState) *dbpkg.WagerEscrow {
+	escrow := &dbpkg.WagerEscrow{
+		EscrowID: state.EscrowID,
+		MatchID:  state.MatchID,
+		Stake:    state.Stake,
+		Status:   dbpkg.EscrowHeld,
+	}
+	for userID := range state.Players {
+		if escrow.PlayerA == "" {
+			escrow.PlayerA = userID
+		} else {
+			escrow.PlayerB = userID
+		}
+	}
+	return escrow
+}
+
+// settleEscrow credits the pot to winnerID, or refunds both players when
+// winnerID is empty, then closes the escrow. Coins move before the status
+// changes; recovery uses the ledger to avoid paying twice.
+func settleEscrow(ctx context.Context, db *sql.DB, nk runtime.NakamaModule, escrow *dbpkg.WagerEscrow, winnerID string) error {
+	var updates []*runtime.WalletUpdate
+	status := dbpkg.EscrowRefunded
+	if winnerID != "" {
+		status = dbpkg.EscrowPaid
+		updates = append(updates, &runtime.WalletUpdate{
+			UserID:    winnerID,
+			Changeset: map[string]int64{WalletCurrency: 2 * escrow.Stake},
+			Metadata:  escrowMetadata(LedgerWagerPayout, escrow),
+		})
+	} else {
+		for _, userID := range []string{escrow.PlayerA, escrow.PlayerB} {
+			updates = append(updates, &runtime.WalletUpdate{
+				UserID:    userID,
+				Changeset: map[string]int64{WalletCurrency: escrow.Stake},
+				Metadata:  escrowMetadata(LedgerWagerRefund, escrow),
+			})
+		}
+	}
+
+	if _, err := nk.WalletsUpdate(ctx, updates, true); err != nil {
+		return err
+	}
+	_, err := dbpkg.NewRepository(db).UpdateEscrowStatus(ctx, escrow.EscrowID, escrow.Status, status, winnerID)
+	return err
+}
+
+// RecoverWagerEscrows settles escrows left open by matches that no longer
+// exist, e.g. after a crash or restart. Stakes that were debited but never
+// paid out are refunded; escrows whose coins already moved are just
+// closed.
+func RecoverWagerEscrows(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule) error {
+	repo := dbpkg.NewRepository(db)
+	escrows, err := repo.ListOpenEscrows(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, escrow := range escrows {
+		if m, err := nk.MatchGet(ctx, escrow.MatchID); err == nil && m != nil {
+			continue
+		}
+
+		reasons, err := escrowLedgerReasons(ctx, nk, escrow)
+		if err != nil {
+			logger.Error("Escrow %s ledger scan failed: %v", escrow.EscrowID, err)
+			continue
+		}
+
+		if escrow.Status == dbpkg.EscrowPending {
+			if !reasons[LedgerWagerEscrow] {
+				if _, err := repo.UpdateEscrowStatus(ctx, escrow.EscrowID, dbpkg.EscrowPending, dbpkg.EscrowCancelled, ""); err != nil {
+					logger.Error("Escrow %s cancel failed: %v", escrow.EscrowID, err)
+				}
+				continue
+			}
+		}
+
+		switch {
+		case reasons[LedgerWagerPayout]:
+			_, err = repo.UpdateEscrowStatus(ctx, escrow.EscrowID, escrow.Status, dbpkg.EscrowPaid, escrow.WinnerID)
+		case reasons[LedgerWagerRefund]:
+			_, err = repo.UpdateEscrowStatus(ctx, escrow.EscrowID, escrow.Status, dbpkg.EscrowRefunded, "")
+		default:
+			err = settleEscrow(ctx, db, nk, escrow, "")
+		}
+		if err != nil {
+			logger.Error("Escrow %s recovery failed: %v", escrow.EscrowID, err)
+			continue
+		}
+		logger.Info("Recovered wager escrow %s from match %s", escrow.EscrowID, escrow.MatchID)
+	}
+	return nil
+}
+
+// escrowLedgerReasons returns which ledger reasons have been recorded for
+// the escrow across both players' wallets.
+func escrowLedgerReasons(ctx context.Context, nk runtime.NakamaModule, escrow *dbpkg.WagerEscrow) (map[string]bool, error) {
+	reasons := make(map[string]bool)
+	for _, userID := range []string{escrow.PlayerA, escrow.PlayerB} {
+		cursor := ""
+		for scanned := 0; scanned < escrowLedgerScanLimit; {
+			items, next, err := nk.WalletLedgerList(ctx, userID, 100, cursor)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				metadata := item.GetMetadata()
+				if id, _ := metadata["escrow_id"].(string); id == escrow.EscrowID {
+					reason, _ := metadata["reason"].(string)
+					reasons[reason] = true
+				}
+			}
+			scanned += len(items)
+			if next == "" || len(items) == 0 {
+				break
+			}
+			cursor = next
+		}
+	}
+	return reasons, nil
+}
+
+func escrowMetadata(reason string, escrow *dbpkg.WagerEscrow) map[string]interface{} {
+	return map[string]interface{}{
+		"reason":    reason,
+		"escrow_id": escrow.EscrowID,
+		"match_id":  escrow.MatchID,
+		"stake":     escrow.Stake,
+	}
+}
@@ -9,14 +9,28 @@ import (
 	"github.com/prasanth-33460/tic-tac-toe/backend/rpc"
 )
 
-// RegisterRoutes wires up the match handler and all RPC endpoints.
+// RegisterRoutes wires up the match handler, server hooks, and all RPC
+// endpoints.
 func RegisterRoutes(_ context.Context, _ runtime.Logger, _ *sql.DB, _ runtime.NakamaModule, init runtime.Initializer) error {
 	if err := registerMatchHandler(init); err != nil {
 		return err
 	}
+	if err := registerHooks(init); err != nil {
+		return err
+	}
 	return registerRPCEndpoints(init)
 }
 
+func registerHooks(init runtime.Initializer) error {
+	if err := init.RegisterMatchmakerMatched(rpc.MatchmakerMatched); err != nil {
+		return err
+	}
+	if err := init.RegisterMatchmakerOverride(rpc.MatchmakerOverride); err != nil {
+		return err
+	}
+	return init.RegisterLeaderboardReset(rpc.SeasonLeaderboardReset)
+}
+
 func registerMatchHandler(init runtime.Initializer) error {
 	return init.RegisterMatch("tictactoe", func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule) (runtime.Match, error) {
 		return &match.Match{}, nil
@@ -25,14 +39,74 @@ func registerMatchHandler(init runtime.Initializer) error {
 
 func registerRPCEndpoints(init runtime.Initializer) error {
 	endpoints := map[string]func(context.Context, runtime.Logger, *sql.DB, runtime.NakamaModule, string) (string, error){
-		"find_match":        rpc.RPCFindMatch,
+		"find_match":         rpc.RPCFindMatch,
 		"create_quick_match": rpc.RPCCreateQuickMatch,
-		"get_match_by_code": rpc.RPCGetMatchIdByCode,
-		"get_match_info":    rpc.RPCGetMatchInfo,
-		"get_leaderboard":   rpc.RPCGetLeaderboard,
-		"request_rematch":   rpc.RPCRequestRematch,
-		"ban_player":        rpc.RPCBanPlayer,
-		"unban_player":      rpc.RPCUnbanPlayer,
+		"get_match_by_code":  rpc.RPCGetMatchIdByCode,
+		"get_match_info":     rpc.RPCGetMatchInfo,
+		"get_leaderboard":    rpc.RPCGetLeaderboard,
+		"request_rematch":    rpc.RPCRequestRematch,
+		"ban_player":         rpc.RPCBanPlayer,
+		"unban_player":       rpc.RPCUnbanPlayer,
+
+		"chat_ban_player":     rpc.RPCChatBanPlayer,
+		"chat_unban_player":   rpc.RPCChatUnbanPlayer,
+		"list_emotes":         rpc.RPCListEmotes,
+		"mute_opponent":       rpc.RPCMuteOpponent,
+		"get_chat_transcript": rpc.RPCGetChatTranscript,
+
+		"report_player":  rpc.RPCReportPlayer,
+		"list_reports":   rpc.RPCListReports,
+		"claim_report":   rpc.RPCClaimReport,
+		"resolve_report": rpc.RPCResolveReport,
+
+		"block_player":         rpc.RPCBlockPlayer,
+		"unblock_player":       rpc.RPCUnblockPlayer,
+		"list_blocked_players": rpc.RPCListBlockedPlayers,
+
+		"create_correspondence_game": rpc.RPCCreateCorrespondenceGame,
+		"submit_correspondence_move": rpc.RPCSubmitCorrespondenceMove,
+		"get_correspondence_game":    rpc.RPCGetCorrespondenceGame,
+		"list_correspondence_games":  rpc.RPCListCorrespondenceGames,
+
+		"create_arena": rpc.RPCCreateArena,
+		"join_arena":   rpc.RPCJoinArena,
+		"leave_arena":  rpc.RPCLeaveArena,
+		"get_arena":    rpc.RPCGetArena,
+
+		"analyze_position": rpc.RPCAnalyzePosition,
+		"get_game_review":  rpc.RPCGetGameReview,
+
+		"create_bot_match":       rpc.RPCCreateBotMatch,
+		"get_learning_bot_stats": rpc.RPCGetLearningBotStats,
+		"register_bot":           rpc.RPCRegisterBot,
+		"deactivate_bot":         rpc.RPCDeactivateBot,
+
+		"get_daily_puzzle":   rpc.RPCGetDailyPuzzle,
+		"submit_puzzle_move": rpc.RPCSubmitPuzzleMove,
+
+		"get_season_history": rpc.RPCGetSeasonHistory,
+		"get_player_stats":   rpc.RPCGetPlayerStats,
+		"list_achievements":  rpc.RPCListAchievements,
+
+		"get_progression":    rpc.RPCGetProgression,
+		"claim_quest_reward": rpc.RPCClaimQuestReward,
+
+		"get_wallet":         rpc.RPCGetWallet,
+		"claim_daily_reward": rpc.RPCClaimDailyReward,
+
+		"get_store_catalogue": rpc.RPCGetStoreCatalogue,
+		"purchase_item":       rpc.RPCPurchaseItem,
+		"equip_item":          rpc.RPCEquipItem,
+
+		"create_clan":       rpc.RPCCreateClan,
+		"join_clan":         rpc.RPCJoinClan,
+		"leave_clan":        rpc.RPCLeaveClan,
+		"get_clan":          rpc.RPCGetClan,
+		"schedule_clan_war": rpc.RPCScheduleClanWar,
+		"join_clan_war":     rpc.RPCJoinClanWar,
+		"leave_clan_war":    rpc.RPCLeaveClanWar,
+		"get_clan_war":      rpc.RPCGetClanWar,
+		"list_clan_wars":    rpc.RPCListClanWars,
 	}
 
 	for id, fn := range endpoints {
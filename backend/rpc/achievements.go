@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+)
+
+// RPCListAchievements returns the full achievement catalogue with the
+// player's unlock timestamps. Defaults to the caller.
+func RPCListAchievements(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	var req AchievementsRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+	if req.UserID == "" {
+		req.UserID, _ = ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	}
+	if req.UserID == "" {
+		return "", fmt.Errorf("user_id required")
+	}
+
+	unlocked, err := dbpkg.NewRepository(db).ListAchievements(ctx, req.UserID)
+	if err != nil {
+		logger.Error("Achievements fetch failed for %s: %v", req.UserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := AchievementsResponse{
+		UserID:       req.UserID,
+		Total:        len(match.Achievements),
+		Achievements: make([]AchievementEntry, 0, len(match.Achievements)),
+	}
+	for _, def := range match.Achievements {
+		entry := AchievementEntry{
+			ID:          def.ID,
+			Title:       def.Title,
+			Description: def.Description,
+		}
+		if at, ok := unlocked[def.ID]; ok {
+			entry.Unlocked = true
+			entry.UnlockedAt = at.Unix()
+			response.Unlocked++
+		}
+		response.Achievements = append(response.Achievements, entry)
+	}
+
+	return marshalResponse(response, logger)
+}
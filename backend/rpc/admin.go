@@ -5,11 +5,16 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
 )
 
+// maxChatBanMatches caps how many running matches a chat ban change is
+// sent to.
+const maxChatBanMatches = 100
+
 // RPCBanPlayer marks a player as banned so they cannot join matches.
 func RPCBanPlayer(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	var req BanRequest
@@ -57,3 +62,76 @@ func RPCUnbanPlayer(ctx context.Context, logger runtime.Logger, db *sql.DB, nk r
 	})
 	return string(resp), nil
 }
+
+// RPCChatBanPlayer bans a player from chat in every match (admin only).
+// They can still play.
+func RPCChatBanPlayer(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return setChatBan(ctx, logger, db, nk, payload, true)
+}
+
+// RPCChatUnbanPlayer lifts a chat ban (admin only).
+func RPCChatUnbanPlayer(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return setChatBan(ctx, logger, db, nk, payload, false)
+}
+
+func setChatBan(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string, banned bool) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	var req BanRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.TargetUserID == "" {
+		return "", fmt.Errorf("target_user_id required")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	if err := repo.SetChatBan(ctx, req.TargetUserID, banned); err != nil {
+		logger.Error("Chat ban update failed for %s: %v", req.TargetUserID, err)
+		return "", fmt.Errorf("chat ban update failed")
+	}
+
+	signalChatBan(ctx, logger, nk, req.TargetUserID, banned)
+
+	message := fmt.Sprintf("Player %s has been banned from chat", req.TargetUserID)
+	if !banned {
+		message = fmt.Sprintf("Player %s can chat again", req.TargetUserID)
+	}
+	logger.Info("%s — reason: %s", message, req.Reason)
+
+	return marshalResponse(BanResponse{Success: true, Message: message}, logger)
+}
+
+// signalChatBan tells running matches about a chat ban change. Matches
+// load the flag when a player joins, so only a player already seated needs
+// telling; matches they are not in ignore the signal.
+func signalChatBan(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, banned bool) {
+	minSize := 1
+	matches, err := nk.MatchList(ctx, maxChatBanMatches, true, "", &minSize, nil, "")
+	if err != nil {
+		logger.Warn("Match list for chat ban of %s failed: %v", userID, err)
+		return
+	}
+
+	signalData, _ := json.Marshal(map[string]interface{}{
+		"type":   "chat_ban",
+		"userId": userID,
+		"banned": banned,
+	})
+	for _, m := range matches {
+		if result, err := nk.MatchSignal(ctx, m.GetMatchId(), string(signalData)); err != nil || strings.HasPrefix(result, "error:") {
+			logger.Warn("Chat ban signal not applied in match %s: %v %s", m.GetMatchId(), err, result)
+		}
+	}
+}
+
+// requireAdmin rejects calls made from a player session. Admin RPCs are
+// invoked server-to-server with the HTTP key, which carries no user ID.
+func requireAdmin(ctx context.Context) error {
+	if userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); userID != "" {
+		return fmt.Errorf("admin access required")
+	}
+	return nil
+}
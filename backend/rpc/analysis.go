@@ -0,0 +1,115 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+	"github.com/prasanth-33460/tic-tac-toe/backend/solver"
+)
+
+// maxAnalysisDepth caps the search depth a client may request on boards
+// larger than 3×3.
+const maxAnalysisDepth = 8
+
+// RPCAnalyzePosition returns the value of a board for the side to move:
+// win, draw or loss with the distance to the end, plus every move ranked.
+// 3×3 boards are solved exactly; larger boards are searched to a limited
+// depth and may come back "unknown".
+func RPCAnalyzePosition(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req AnalyzeRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.Depth < 0 || req.Depth > maxAnalysisDepth {
+		return "", fmt.Errorf("depth must be between 0 and %d", maxAnalysisDepth)
+	}
+
+	board, err := solver.NewBoard(req.Board, req.WinLength)
+	if err != nil {
+		return "", err
+	}
+	board.Misere = req.Misere
+	analysis, err := solver.Analyze(board, req.ToMove, req.Depth)
+	if err != nil {
+		return "", err
+	}
+	return marshalResponse(analysis, logger)
+}
+
+// RPCGetGameReview returns the solver's review of a finished game: every
+// move labelled best, inaccuracy or blunder, the ply where the result
+// became forced, and each player's accuracy. Reviews are stored with the
+// match history; games recorded before reviews existed are reviewed on
+// first request and stored.
+func RPCGetGameReview(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	var req GameReviewRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.MatchID == "" {
+		return "", fmt.Errorf("match_id required")
+	}
+
+	gameIndex := dbpkg.LatestGame
+	if req.Game != nil {
+		if *req.Game < 0 {
+			return "", fmt.Errorf("invalid game")
+		}
+		gameIndex = *req.Game
+	}
+
+	repo := dbpkg.NewRepository(db)
+	rec, err := repo.GetMatchRecord(ctx, req.MatchID, gameIndex)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("match not found")
+	}
+	if err != nil {
+		logger.Error("Match record fetch failed for %s: %v", req.MatchID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	// Players may review their own games; admins any game.
+	if userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); userID != "" {
+		var players []string
+		if err := json.Unmarshal(rec.Players, &players); err != nil || !slices.Contains(players, userID) {
+			return "", fmt.Errorf("match not found")
+		}
+	}
+
+	if len(rec.Review) > 0 {
+		return marshalResponse(map[string]interface{}{"match_id": rec.MatchID, "game": rec.GameIndex, "used_hints": rec.UsedHints, "review": json.RawMessage(rec.Review)}, logger)
+	}
+
+	if rec.Mode == match.ModeVanishing {
+		return "", fmt.Errorf("reviews are not available for vanishing games")
+	}
+
+	var moves []match.MoveRecord
+	if err := json.Unmarshal(rec.Moves, &moves); err != nil || len(moves) == 0 {
+		return "", fmt.Errorf("no moves recorded for this match")
+	}
+	review, err := match.ReviewGame(moves, match.WinnerSymbol(moves, rec.WinnerID), rec.Mode == match.ModeMisere)
+	if err != nil {
+		logger.Error("Game review failed for %s: %v", req.MatchID, err)
+		return "", fmt.Errorf("review failed")
+	}
+	reviewJSON, err := json.Marshal(review)
+	if err != nil {
+		return "", fmt.Errorf("internal error")
+	}
+	if err := repo.SetMatchReview(ctx, req.MatchID, rec.GameIndex, reviewJSON); err != nil {
+		logger.Error("Game review store failed for %s: %v", req.MatchID, err)
+	}
+
+	return marshalResponse(map[string]interface{}{"match_id": rec.MatchID, "game": rec.GameIndex, "used_hints": rec.UsedHints, "review": review}, logger)
+}
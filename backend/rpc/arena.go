@@ -0,0 +1,227 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
+)
+
+const arenaStandingsLimit = 50
+
+// RPCCreateArena starts a time-boxed arena event (admin only). Players join
+// with join_arena and are paired continuously until the arena ends.
+func RPCCreateArena(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	var req ArenaCreateRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+	if req.Mode == "" {
+		req.Mode = match.ModeClassic
+	}
+	if req.Mode != match.ModeClassic && req.Mode != match.ModeTimed {
+		return "", fmt.Errorf("unsupported arena mode: %s", req.Mode)
+	}
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = match.DefaultArenaMinutes
+	}
+	if req.DurationMinutes < 1 || req.DurationMinutes > match.MaxArenaMinutes {
+		return "", fmt.Errorf("duration_minutes must be between 1 and %d", match.MaxArenaMinutes)
+	}
+
+	now := time.Now()
+	arena := &dbpkg.Arena{
+		ArenaID:  utils.NewID(""),
+		Mode:     req.Mode,
+		StartsAt: now,
+		EndsAt:   now.Add(time.Duration(req.DurationMinutes) * time.Minute),
+	}
+
+	leaderboardID := match.ArenaLeaderboardID(arena.ArenaID)
+	metadata := map[string]interface{}{"arena_id": arena.ArenaID, "ends_at": arena.EndsAt.Unix()}
+	if err := nk.LeaderboardCreate(ctx, leaderboardID, true, "desc", "set", "", metadata); err != nil {
+		logger.Error("Arena leaderboard create failed: %v", err)
+		return "", fmt.Errorf("arena creation failed")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	if err := repo.CreateArena(ctx, arena); err != nil {
+		logger.Error("Arena create failed: %v", err)
+		return "", fmt.Errorf("arena creation failed")
+	}
+
+	logger.Info("Arena %s created — mode: %s, ends: %s", arena.ArenaID, arena.Mode, arena.EndsAt)
+	return marshalResponse(toArenaResponse(arena, now), logger)
+}
+
+// RPCJoinArena puts the caller into an arena's pairing pool and tries to
+// pair them straight away. A returning player keeps their points.
+func RPCJoinArena(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+	username, _ := ctx.Value(runtime.RUNTIME_CTX_USERNAME).(string)
+
+	repo := dbpkg.NewRepository(db)
+	arena, err := loadArena(ctx, repo, payload)
+	if err != nil {
+		return "", err
+	}
+	if !arena.IsRunning(time.Now()) {
+		return "", fmt.Errorf("arena is not running")
+	}
+
+	if banned, err := repo.IsPlayerBanned(ctx, userID); err == nil && banned {
+		return "", fmt.Errorf("player is banned")
+	}
+
+	rating, err := repo.GetSkillRating(ctx, userID)
+	if err != nil {
+		logger.Warn("Skill rating fetch failed for %s: %v", userID, err)
+		rating = dbpkg.DefaultSkillRating
+	}
+
+	resetStaleArenaSeat(ctx, logger, nk, repo, arena.ArenaID, userID)
+	if err := repo.JoinArena(ctx, arena.ArenaID, userID, username, rating); err != nil {
+		logger.Error("Arena join failed for %s: %v", userID, err)
+		return "", fmt.Errorf("join failed")
+	}
+
+	match.PairArenaPlayers(ctx, logger, db, nk, arena.ArenaID)
+	return arenaResponse(ctx, logger, nk, repo, arena, userID)
+}
+
+// RPCLeaveArena takes the caller out of the pairing pool. Points already
+// earned stay on the arena leaderboard.
+func RPCLeaveArena(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	arena, err := loadArena(ctx, repo, payload)
+	if err != nil {
+		return "", err
+	}
+
+	resetStaleArenaSeat(ctx, logger, nk, repo, arena.ArenaID, userID)
+	if err := repo.LeaveArena(ctx, arena.ArenaID, userID); err != nil {
+		logger.Error("Arena leave failed for %s: %v", userID, err)
+		return "", fmt.Errorf("leave failed")
+	}
+	return arenaResponse(ctx, logger, nk, repo, arena, userID)
+}
+
+// RPCGetArena returns an arena's live standings and the caller's own
+// progress. Polling it also retries pairing for anyone still waiting.
+func RPCGetArena(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+
+	repo := dbpkg.NewRepository(db)
+	arena, err := loadArena(ctx, repo, payload)
+	if err != nil {
+		return "", err
+	}
+
+	if arena.IsRunning(time.Now()) {
+		match.PairArenaPlayers(ctx, logger, db, nk, arena.ArenaID)
+	}
+	return arenaResponse(ctx, logger, nk, repo, arena, userID)
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+func loadArena(ctx context.Context, repo *dbpkg.Repository, payload string) (*dbpkg.Arena, error) {
+	var req ArenaRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return nil, fmt.Errorf("invalid request")
+	}
+	if req.ArenaID == "" {
+		return nil, fmt.Errorf("arena_id required")
+	}
+
+	arena, err := repo.GetArena(ctx, req.ArenaID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("arena not found")
+		}
+		return nil, fmt.Errorf("internal error")
+	}
+	return arena, nil
+}
+
+// resetStaleArenaSeat frees a player the arena still has as playing in a
+// match that no longer exists, so they can join again or leave.
+func resetStaleArenaSeat(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, repo *dbpkg.Repository, arenaID, userID string) {
+	p, err := repo.GetArenaPlayer(ctx, arenaID, userID)
+	if err != nil || p.Status != dbpkg.ArenaPlaying || matchRunning(ctx, nk, p.MatchID) {
+		return
+	}
+	if err := repo.ResetArenaPlayer(ctx, arenaID, userID, p.MatchID); err != nil {
+		logger.Error("Arena seat reset failed for %s: %v", userID, err)
+	}
+}
+
+// matchRunning reports whether the match still exists on this server.
+func matchRunning(ctx context.Context, nk runtime.NakamaModule, matchID string) bool {
+	if matchID == "" {
+		return false
+	}
+	m, err := nk.MatchGet(ctx, matchID)
+	return err == nil && m != nil
+}
+
+func arenaResponse(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, repo *dbpkg.Repository, arena *dbpkg.Arena, userID string) (string, error) {
+	response := toArenaResponse(arena, time.Now())
+
+	records, _, _, _, err := nk.LeaderboardRecordsList(ctx, response.LeaderboardID, nil, arenaStandingsLimit, "", 0)
+	if err != nil {
+		logger.Error("Arena standings fetch failed for %s: %v", arena.ArenaID, err)
+	}
+	for _, r := range records {
+		response.Standings = append(response.Standings, toLeaderboardEntry(r))
+	}
+
+	if userID != "" {
+		if p, err := repo.GetArenaPlayer(ctx, arena.ArenaID, userID); err == nil {
+			response.Me = &ArenaStanding{
+				Status:      p.Status,
+				MatchID:     p.MatchID,
+				Points:      p.Points,
+				Streak:      p.Streak,
+				GamesPlayed: p.GamesPlayed,
+			}
+		}
+	}
+
+	return marshalResponse(response, logger)
+}
+
+func toArenaResponse(arena *dbpkg.Arena, now time.Time) ArenaResponse {
+	return ArenaResponse{
+		ArenaID:       arena.ArenaID,
+		Mode:          arena.Mode,
+		StartsAt:      arena.StartsAt.Unix(),
+		EndsAt:        arena.EndsAt.Unix(),
+		Running:       arena.IsRunning(now),
+		LeaderboardID: match.ArenaLeaderboardID(arena.ArenaID),
+		Standings:     []LeaderboardEntry{},
+	}
+}
@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+// maxBlockedPlayers caps the size of one player's block list.
+const maxBlockedPlayers = 500
+
+// RPCBlockPlayer adds a player to the caller's block list. Blocked pairs
+// are never matched together and cannot challenge each other. When a
+// match_id is given, the blocked player's chat is hidden in that match
+// straight away.
+func RPCBlockPlayer(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req BlockRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.TargetUserID == "" {
+		return "", fmt.Errorf("target_user_id required")
+	}
+	if req.TargetUserID == userID {
+		return "", fmt.Errorf("cannot block yourself")
+	}
+
+	users, err := nk.UsersGetId(ctx, []string{req.TargetUserID}, nil)
+	if err != nil || len(users) == 0 {
+		return "", fmt.Errorf("player not found")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	count, err := repo.CountBlockedPlayers(ctx, userID)
+	if err != nil {
+		logger.Error("Block list count failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if count >= maxBlockedPlayers {
+		return "", fmt.Errorf("block list is full (%d players)", maxBlockedPlayers)
+	}
+
+	if err := repo.BlockPlayer(ctx, userID, req.TargetUserID); err != nil {
+		logger.Error("Block failed for %s -> %s: %v", userID, req.TargetUserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	if req.MatchID != "" {
+		signalData, _ := json.Marshal(map[string]string{
+			"type":     "block_player",
+			"userId":   userID,
+			"targetId": req.TargetUserID,
+		})
+		if result, err := nk.MatchSignal(ctx, req.MatchID, string(signalData)); err != nil || strings.HasPrefix(result, "error:") {
+			logger.Warn("Block signal not applied in match %s: %v %s", req.MatchID, err, result)
+		}
+	}
+
+	return marshalResponse(map[string]interface{}{"success": true, "target_user_id": req.TargetUserID}, logger)
+}
+
+// RPCUnblockPlayer removes a player from the caller's block list.
+func RPCUnblockPlayer(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req BlockRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.TargetUserID == "" {
+		return "", fmt.Errorf("target_user_id required")
+	}
+
+	if err := dbpkg.NewRepository(db).UnblockPlayer(ctx, userID, req.TargetUserID); err != nil {
+		logger.Error("Unblock failed for %s -> %s: %v", userID, req.TargetUserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	return marshalResponse(map[string]interface{}{"success": true, "target_user_id": req.TargetUserID}, logger)
+}
+
+// RPCListBlockedPlayers returns the caller's block list.
+func RPCListBlockedPlayers(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	blocked, err := dbpkg.NewRepository(db).ListBlockedPlayers(ctx, userID)
+	if err != nil {
+		logger.Error("Block list fetch failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	ids := make([]string, len(blocked))
+	for i, b := range blocked {
+		ids[i] = b.UserID
+	}
+	usernames := make(map[string]string, len(ids))
+	if len(ids) > 0 {
+		users, err := nk.UsersGetId(ctx, ids, nil)
+		if err != nil {
+			logger.Warn("Blocked user lookup failed for %s: %v", userID, err)
+		}
+		for _, u := range users {
+			usernames[u.GetId()] = u.GetUsername()
+		}
+	}
+
+	response := BlockListResponse{Players: make([]BlockedPlayerResponse, 0, len(blocked))}
+	for _, b := range blocked {
+		response.Players = append(response.Players, BlockedPlayerResponse{
+			UserID:    b.UserID,
+			Username:  usernames[b.UserID],
+			BlockedAt: b.CreatedAt.Unix(),
+		})
+	}
+	return marshalResponse(response, logger)
+}
@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+)
+
+// maxBotNameLength matches the bot_accounts.name column.
+const maxBotNameLength = 64
+
+// RPCCreateBotMatch creates a casual match against the server's bot. The
+// caller joins it like any other match and the bot takes the other seat.
+func RPCCreateBotMatch(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	req := parseMatchRequest(payload, logger)
+	if req.Bot == "" {
+		return "", fmt.Errorf("bot must be one of %s", strings.Join(match.BotDifficulties, ", "))
+	}
+	req.Casual = true
+
+	params, err := matchParams(req)
+	if err != nil {
+		return "", err
+	}
+	matchID, err := nk.MatchCreate(ctx, "tictactoe", params)
+	if err != nil {
+		logger.Error("Bot match creation failed: %v", err)
+		return "", fmt.Errorf("match creation failed")
+	}
+
+	logger.Info("Bot match %s created for %s — bot: %s", matchID, userID, req.Bot)
+	return marshalResponse(map[string]interface{}{
+		"matchId": matchID,
+		"mode":    req.Mode,
+		"bot":     req.Bot,
+		"casual":  true,
+	}, logger)
+}
+
+// RPCGetLearningBotStats returns the learning bot's results overall and per
+// day.
+func RPCGetLearningBotStats(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	stats, _, err := match.LoadLearningStats(ctx, nk)
+	if err != nil {
+		logger.Error("Learning bot stats read failed: %v", err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := LearningStatsResponse{
+		Games:   stats.Games,
+		Wins:    stats.Wins,
+		Draws:   stats.Draws,
+		Losses:  stats.Losses,
+		WinRate: winRate(stats.Wins, stats.Games),
+		Days:    make([]LearningDayResponse, 0, len(stats.Days)),
+	}
+	for _, day := range stats.Days {
+		response.Days = append(response.Days, LearningDayResponse{
+			LearningDay: day,
+			WinRate:     winRate(day.Wins, day.Games),
+		})
+	}
+	return marshalResponse(response, logger)
+}
+
+// winRate is wins as a percentage of games, to one decimal place.
+func winRate(wins, games int) float64 {
+	if games == 0 {
+		return 0
+	}
+	return math.Round(float64(wins)/float64(games)*1000) / 10
+}
+
+// RPCRegisterBot marks an existing account as an external bot (admin
+// only). From then on it must join matches with the bot flag, it is kept
+// off the player leaderboards and plays on the bot ladder.
+func RPCRegisterBot(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	var req RegisterBotRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.TargetUserID == "" {
+		return "", fmt.Errorf("target_user_id required")
+	}
+
+	users, err := nk.UsersGetId(ctx, []string{req.TargetUserID}, nil)
+	if err != nil || len(users) == 0 {
+		return "", fmt.Errorf("player not found")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		req.Name = users[0].GetUsername()
+	}
+	if len(req.Name) > maxBotNameLength {
+		return "", fmt.Errorf("name must be at most %d characters", maxBotNameLength)
+	}
+
+	if err := dbpkg.NewRepository(db).RegisterBot(ctx, req.TargetUserID, req.Name, req.Owner); err != nil {
+		logger.Error("Bot registration failed for %s: %v", req.TargetUserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	logger.Info("Bot registered: %s (%s)", req.Name, req.TargetUserID)
+	return marshalResponse(map[string]interface{}{"success": true, "user_id": req.TargetUserID, "name": req.Name}, logger)
+}
+
+// RPCDeactivateBot stops a registered bot from joining matches (admin
+// only). Its record and ladder entry are kept.
+func RPCDeactivateBot(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	var req RegisterBotRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.TargetUserID == "" {
+		return "", fmt.Errorf("target_user_id required")
+	}
+
+	found, err := dbpkg.NewRepository(db).DeactivateBot(ctx, req.TargetUserID)
+	if err != nil {
+		logger.Error("Bot deactivation failed for %s: %v", req.TargetUserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if !found {
+		return "", fmt.Errorf("not a registered bot")
+	}
+	return marshalResponse(map[string]interface{}{"success": true, "user_id": req.TargetUserID}, logger)
+}
@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+)
+
+// maxTranscriptLimit caps how many messages one transcript call returns.
+const maxTranscriptLimit = 500
+
+// RPCListEmotes returns the quick-chat emote set clients can send with
+// OpCodeEmote.
+func RPCListEmotes(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return marshalResponse(map[string]interface{}{"emotes": match.Emotes}, logger)
+}
+
+// RPCMuteOpponent hides (or shows again) the opponent's chat messages from
+// the caller for the rest of the match.
+func RPCMuteOpponent(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req MuteRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.MatchID == "" {
+		return "", fmt.Errorf("match_id required")
+	}
+
+	signalData, err := json.Marshal(map[string]interface{}{
+		"type":   "mute_opponent",
+		"userId": userID,
+		"muted":  req.Muted,
+	})
+	if err != nil {
+		return "", fmt.Errorf("internal error")
+	}
+
+	result, err := nk.MatchSignal(ctx, req.MatchID, string(signalData))
+	if err != nil {
+		logger.Error("Mute signal failed for match %s: %v", req.MatchID, err)
+		return "", fmt.Errorf("mute failed")
+	}
+	if strings.HasPrefix(result, "error:") {
+		return "", fmt.Errorf("mute failed")
+	}
+
+	return marshalResponse(map[string]interface{}{"success": true, "muted": req.Muted}, logger)
+}
+
+// RPCGetChatTranscript returns a match's stored chat. Players can read the
+// transcript of a live match they are in; admins can read any match's,
+// including after it has ended.
+func RPCGetChatTranscript(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	var req TranscriptRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.MatchID == "" {
+		return "", fmt.Errorf("match_id required")
+	}
+	if req.Limit <= 0 || req.Limit > maxTranscriptLimit {
+		req.Limit = maxTranscriptLimit
+	}
+
+	if userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); userID != "" {
+		if !isMatchParticipant(ctx, nk, req.MatchID, userID) {
+			return "", fmt.Errorf("transcript not available")
+		}
+	}
+
+	messages, err := dbpkg.NewRepository(db).GetChatTranscript(ctx, req.MatchID, req.Limit)
+	if err != nil {
+		logger.Error("Transcript fetch failed for %s: %v", req.MatchID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := TranscriptResponse{MatchID: req.MatchID, Messages: make([]TranscriptEntry, 0, len(messages))}
+	for _, m := range messages {
+		response.Messages = append(response.Messages, TranscriptEntry{
+			UserID:    m.UserID,
+			Username:  m.Username,
+			Message:   m.Message,
+			Timestamp: m.CreatedAt.Unix(),
+		})
+	}
+	return marshalResponse(response, logger)
+}
+
+// isMatchParticipant asks a live match whether userID is one of its
+// players. Ended matches no longer answer, so this is false for them.
+func isMatchParticipant(ctx context.Context, nk runtime.NakamaModule, matchID, userID string) bool {
+	signalData, err := json.Marshal(map[string]string{
+		"type":   "participant_check",
+		"userId": userID,
+	})
+	if err != nil {
+		return false
+	}
+
+	result, err := nk.MatchSignal(ctx, matchID, string(signalData))
+	return err == nil && result == "participant"
+}
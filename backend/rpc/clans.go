@@ -0,0 +1,440 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
+)
+
+// RPCCreateClan creates a clan (a Nakama group marked as a clan) with the
+// caller as its superadmin. A player can only be in one clan.
+func RPCCreateClan(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req ClanCreateRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil || req.Name == "" {
+		return "", fmt.Errorf("invalid request")
+	}
+
+	if err := requireNoClan(ctx, logger, nk, userID); err != nil {
+		return "", err
+	}
+
+	metadata := map[string]interface{}{match.ClanMetadataKey: true}
+	group, err := nk.GroupCreate(ctx, userID, req.Name, userID, "", req.Description, "", req.Open, metadata, match.MaxClanMembers)
+	if err != nil {
+		logger.Warn("Clan create failed for %s: %v", userID, err)
+		return "", fmt.Errorf("clan creation failed")
+	}
+
+	logger.Info("Clan %s (%s) created by %s", group.GetName(), group.GetId(), userID)
+	return clanResponse(ctx, logger, nk, group)
+}
+
+// RPCJoinClan joins the caller to a clan. Closed clans receive a join
+// request instead, which a clan admin accepts through Nakama's group API.
+func RPCJoinClan(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+	username, _ := ctx.Value(runtime.RUNTIME_CTX_USERNAME).(string)
+
+	group, err := loadClan(ctx, nk, payload)
+	if err != nil {
+		return "", err
+	}
+	if err := requireNoClan(ctx, logger, nk, userID); err != nil {
+		return "", err
+	}
+
+	if err := nk.GroupUserJoin(ctx, group.GetId(), userID, username); err != nil {
+		logger.Warn("Clan join failed for %s: %v", userID, err)
+		return "", fmt.Errorf("join failed")
+	}
+	return clanResponse(ctx, logger, nk, group)
+}
+
+// RPCLeaveClan removes the caller from their clan. Wins already counted
+// stay with the clan.
+func RPCLeaveClan(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+	username, _ := ctx.Value(runtime.RUNTIME_CTX_USERNAME).(string)
+
+	clan, err := match.ClanForUser(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Clan lookup failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if clan == nil {
+		return "", fmt.Errorf("not in a clan")
+	}
+
+	if err := nk.GroupUserLeave(ctx, clan.GetId(), userID, username); err != nil {
+		logger.Warn("Clan leave failed for %s: %v", userID, err)
+		return "", fmt.Errorf("leave failed")
+	}
+	return marshalResponse(map[string]interface{}{"success": true, "clan_id": clan.GetId()}, logger)
+}
+
+// RPCGetClan returns a clan and its standing on the clan leaderboard.
+// Without a clan_id it returns the caller's clan.
+func RPCGetClan(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	var req ClanRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+
+	if req.ClanID == "" {
+		userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if userID == "" {
+			return "", fmt.Errorf("clan_id required")
+		}
+		clan, err := match.ClanForUser(ctx, nk, userID)
+		if err != nil {
+			logger.Error("Clan lookup failed for %s: %v", userID, err)
+			return "", fmt.Errorf("internal error")
+		}
+		if clan == nil {
+			return "", fmt.Errorf("not in a clan")
+		}
+		return clanResponse(ctx, logger, nk, clan)
+	}
+
+	group, err := loadClan(ctx, nk, payload)
+	if err != nil {
+		return "", err
+	}
+	return clanResponse(ctx, logger, nk, group)
+}
+
+// RPCScheduleClanWar schedules a war between two clans (admin only).
+// During the war members join with join_clan_war and are paired against
+// members of the other clan.
+func RPCScheduleClanWar(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	var req ClanWarCreateRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.ClanA == "" || req.ClanB == "" || req.ClanA == req.ClanB {
+		return "", fmt.Errorf("two different clans required")
+	}
+	if req.Mode == "" {
+		req.Mode = match.ModeClassic
+	}
+	if req.Mode != match.ModeClassic && req.Mode != match.ModeTimed {
+		return "", fmt.Errorf("unsupported clan war mode: %s", req.Mode)
+	}
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = match.DefaultClanWarMinutes
+	}
+	if req.DurationMinutes < 1 || req.DurationMinutes > match.MaxClanWarMinutes {
+		return "", fmt.Errorf("duration_minutes must be between 1 and %d", match.MaxClanWarMinutes)
+	}
+
+	now := time.Now()
+	startsAt := now
+	if req.StartsAt != 0 {
+		startsAt = time.Unix(req.StartsAt, 0)
+	}
+	if startsAt.Before(now.Add(-time.Minute)) || startsAt.After(now.AddDate(0, 0, match.MaxClanWarScheduleDays)) {
+		return "", fmt.Errorf("starts_at must be within the next %d days", match.MaxClanWarScheduleDays)
+	}
+
+	groups, err := nk.GroupsGetId(ctx, []string{req.ClanA, req.ClanB})
+	if err != nil {
+		logger.Error("Clan lookup failed: %v", err)
+		return "", fmt.Errorf("internal error")
+	}
+	if len(groups) != 2 || !match.IsClan(groups[0]) || !match.IsClan(groups[1]) {
+		return "", fmt.Errorf("clan not found")
+	}
+
+	war := &dbpkg.ClanWar{
+		WarID:    utils.NewID(""),
+		ClanA:    req.ClanA,
+		ClanB:    req.ClanB,
+		Mode:     req.Mode,
+		StartsAt: startsAt,
+		EndsAt:   startsAt.Add(time.Duration(req.DurationMinutes) * time.Minute),
+	}
+	if err := dbpkg.NewRepository(db).CreateClanWar(ctx, war); err != nil {
+		logger.Error("Clan war create failed: %v", err)
+		return "", fmt.Errorf("clan war creation failed")
+	}
+
+	logger.Info("Clan war %s scheduled — %s vs %s, %s to %s", war.WarID, war.ClanA, war.ClanB, war.StartsAt, war.EndsAt)
+	return marshalResponse(toClanWarResponse(war, now), logger)
+}
+
+// RPCJoinClanWar puts the caller into a running war's pairing pool and
+// tries to pair them straight away. Only members of the two clans can join.
+func RPCJoinClanWar(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+	username, _ := ctx.Value(runtime.RUNTIME_CTX_USERNAME).(string)
+
+	repo := dbpkg.NewRepository(db)
+	war, err := loadClanWar(ctx, repo, payload)
+	if err != nil {
+		return "", err
+	}
+	if !war.IsRunning(time.Now()) {
+		return "", fmt.Errorf("clan war is not running")
+	}
+
+	clan, err := match.ClanForUser(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Clan lookup failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if clan == nil || (clan.GetId() != war.ClanA && clan.GetId() != war.ClanB) {
+		return "", fmt.Errorf("not a member of either clan")
+	}
+
+	if banned, err := repo.IsPlayerBanned(ctx, userID); err == nil && banned {
+		return "", fmt.Errorf("player is banned")
+	}
+
+	rating, err := repo.GetSkillRating(ctx, userID)
+	if err != nil {
+		logger.Warn("Skill rating fetch failed for %s: %v", userID, err)
+		rating = dbpkg.DefaultSkillRating
+	}
+
+	resetStaleClanWarSeat(ctx, logger, nk, repo, war.WarID, userID)
+	if err := repo.JoinClanWar(ctx, war.WarID, userID, clan.GetId(), username, rating); err != nil {
+		logger.Error("Clan war join failed for %s: %v", userID, err)
+		return "", fmt.Errorf("join failed")
+	}
+
+	match.PairClanWarPlayers(ctx, logger, db, nk, war.WarID)
+	return clanWarResponse(ctx, logger, repo, war, userID)
+}
+
+// RPCLeaveClanWar takes the caller out of the war's pairing pool. Points
+// already scored stay with the clan.
+func RPCLeaveClanWar(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	war, err := loadClanWar(ctx, repo, payload)
+	if err != nil {
+		return "", err
+	}
+
+	resetStaleClanWarSeat(ctx, logger, nk, repo, war.WarID, userID)
+	if err := repo.LeaveClanWar(ctx, war.WarID, userID); err != nil {
+		logger.Error("Clan war leave failed for %s: %v", userID, err)
+		return "", fmt.Errorf("leave failed")
+	}
+	return clanWarResponse(ctx, logger, repo, war, userID)
+}
+
+// RPCGetClanWar returns a war's score and the caller's own progress.
+// Polling it also retries pairing for anyone still waiting.
+func RPCGetClanWar(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+
+	repo := dbpkg.NewRepository(db)
+	war, err := loadClanWar(ctx, repo, payload)
+	if err != nil {
+		return "", err
+	}
+
+	if war.IsRunning(time.Now()) {
+		match.PairClanWarPlayers(ctx, logger, db, nk, war.WarID)
+		updated, err := repo.GetClanWar(ctx, war.WarID)
+		if err != nil {
+			logger.Error("Clan war reload failed for %s: %v", war.WarID, err)
+			return "", fmt.Errorf("internal error")
+		}
+		war = updated
+	}
+	return clanWarResponse(ctx, logger, repo, war, userID)
+}
+
+// RPCListClanWars returns the upcoming and running wars of a clan.
+// Without a clan_id it uses the caller's clan.
+func RPCListClanWars(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	var req ClanRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+
+	if req.ClanID == "" {
+		userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if userID == "" {
+			return "", fmt.Errorf("clan_id required")
+		}
+		clan, err := match.ClanForUser(ctx, nk, userID)
+		if err != nil {
+			logger.Error("Clan lookup failed for %s: %v", userID, err)
+			return "", fmt.Errorf("internal error")
+		}
+		if clan == nil {
+			return "", fmt.Errorf("not in a clan")
+		}
+		req.ClanID = clan.GetId()
+	}
+
+	wars, err := dbpkg.NewRepository(db).ListClanWars(ctx, req.ClanID)
+	if err != nil {
+		logger.Error("Clan war list failed for %s: %v", req.ClanID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	now := time.Now()
+	response := ClanWarListResponse{ClanID: req.ClanID, Wars: make([]ClanWarResponse, 0, len(wars))}
+	for _, w := range wars {
+		response.Wars = append(response.Wars, toClanWarResponse(w, now))
+	}
+	return marshalResponse(response, logger)
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+func requireNoClan(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) error {
+	clan, err := match.ClanForUser(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Clan lookup failed for %s: %v", userID, err)
+		return fmt.Errorf("internal error")
+	}
+	if clan != nil {
+		return fmt.Errorf("already in a clan")
+	}
+	return nil
+}
+
+func loadClan(ctx context.Context, nk runtime.NakamaModule, payload string) (*api.Group, error) {
+	var req ClanRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return nil, fmt.Errorf("invalid request")
+	}
+	if req.ClanID == "" {
+		return nil, fmt.Errorf("clan_id required")
+	}
+
+	groups, err := nk.GroupsGetId(ctx, []string{req.ClanID})
+	if err != nil {
+		return nil, fmt.Errorf("internal error")
+	}
+	if len(groups) == 0 || !match.IsClan(groups[0]) {
+		return nil, fmt.Errorf("clan not found")
+	}
+	return groups[0], nil
+}
+
+func clanResponse(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, group *api.Group) (string, error) {
+	response := ClanResponse{
+		ClanID:      group.GetId(),
+		Name:        group.GetName(),
+		Description: group.GetDescription(),
+		Open:        group.GetOpen().GetValue(),
+		Members:     int(group.GetEdgeCount()),
+	}
+
+	_, owners, _, _, err := nk.LeaderboardRecordsList(ctx, match.LeaderboardClanWins, []string{group.GetId()}, 1, "", 0)
+	if err != nil {
+		logger.Warn("Clan leaderboard fetch failed for %s: %v", group.GetId(), err)
+	}
+	if len(owners) > 0 {
+		response.Wins = owners[0].GetScore()
+		response.Rank = owners[0].GetRank()
+	}
+
+	return marshalResponse(response, logger)
+}
+
+func loadClanWar(ctx context.Context, repo *dbpkg.Repository, payload string) (*dbpkg.ClanWar, error) {
+	var req ClanWarRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return nil, fmt.Errorf("invalid request")
+	}
+	if req.WarID == "" {
+		return nil, fmt.Errorf("war_id required")
+	}
+
+	war, err := repo.GetClanWar(ctx, req.WarID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("clan war not found")
+		}
+		return nil, fmt.Errorf("internal error")
+	}
+	return war, nil
+}
+
+// resetStaleClanWarSeat frees a member the war still has as playing in a
+// match that no longer exists, so they can join again or leave.
+func resetStaleClanWarSeat(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, repo *dbpkg.Repository, warID, userID string) {
+	p, err := repo.GetClanWarPlayer(ctx, warID, userID)
+	if err != nil || p.Status != dbpkg.ArenaPlaying || matchRunning(ctx, nk, p.MatchID) {
+		return
+	}
+	if err := repo.ResetClanWarPlayer(ctx, warID, userID, p.MatchID); err != nil {
+		logger.Error("Clan war seat reset failed for %s: %v", userID, err)
+	}
+}
+
+func clanWarResponse(ctx context.Context, logger runtime.Logger, repo *dbpkg.Repository, war *dbpkg.ClanWar, userID string) (string, error) {
+	response := toClanWarResponse(war, time.Now())
+
+	if userID != "" {
+		if p, err := repo.GetClanWarPlayer(ctx, war.WarID, userID); err == nil {
+			response.Me = &ClanWarStanding{
+				ClanID:      p.ClanID,
+				Status:      p.Status,
+				MatchID:     p.MatchID,
+				Points:      p.Points,
+				GamesPlayed: p.GamesPlayed,
+			}
+		}
+	}
+
+	return marshalResponse(response, logger)
+}
+
+func toClanWarResponse(war *dbpkg.ClanWar, now time.Time) ClanWarResponse {
+	return ClanWarResponse{
+		WarID:    war.WarID,
+		ClanA:    war.ClanA,
+		ClanB:    war.ClanB,
+		Mode:     war.Mode,
+		StartsAt: war.StartsAt.Unix(),
+		EndsAt:   war.EndsAt.Unix(),
+		Running:  war.IsRunning(now),
+		ScoreA:   war.ScoreA,
+		ScoreB:   war.ScoreB,
+	}
+}
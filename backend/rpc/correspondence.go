@@ -0,0 +1,328 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
+)
+
+const correspondenceListLimit = 100
+
+// RPCCreateCorrespondenceGame starts an asynchronous game against another
+// player. The caller plays X and moves first; the opponent is notified.
+// This is the friend challenge: the only way to start a game against a
+// chosen player, so it refuses pairs where either has blocked the other.
+// Live matches joined by short code are covered by ValidateJoinRequest.
+func RPCCreateCorrespondenceGame(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req CorrespondenceCreateRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.OpponentID == "" {
+		return "", fmt.Errorf("opponent_id required")
+	}
+	if req.OpponentID == userID {
+		return "", fmt.Errorf("cannot challenge yourself")
+	}
+
+	moveHours := req.MoveHours
+	if moveHours == 0 {
+		moveHours = match.DefaultCorrespondenceMoveHours
+	}
+	if moveHours < match.MinCorrespondenceMoveHours || moveHours > match.MaxCorrespondenceMoveHours {
+		return "", fmt.Errorf("move_hours must be between %d and %d", match.MinCorrespondenceMoveHours, match.MaxCorrespondenceMoveHours)
+	}
+
+	users, err := nk.UsersGetId(ctx, []string{userID, req.OpponentID}, nil)
+	if err != nil {
+		logger.Error("User lookup failed: %v", err)
+		return "", fmt.Errorf("internal error")
+	}
+	usernames := make(map[string]string, len(users))
+	for _, u := range users {
+		usernames[u.GetId()] = u.GetUsername()
+	}
+	if _, ok := usernames[req.OpponentID]; !ok {
+		return "", fmt.Errorf("opponent not found")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	blocked, err := repo.IsBlockedEither(ctx, userID, req.OpponentID)
+	if err != nil {
+		logger.Error("Block lookup failed for %s/%s: %v", userID, req.OpponentID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if blocked {
+		return "", fmt.Errorf("cannot challenge this player")
+	}
+	for _, id := range []string{userID, req.OpponentID} {
+		if banned, err := repo.IsPlayerBanned(ctx, id); err == nil && banned {
+			return "", fmt.Errorf("player is banned")
+		}
+		count, err := repo.CountActiveCorrespondenceGames(ctx, id)
+		if err != nil {
+			logger.Error("Correspondence count failed for %s: %v", id, err)
+			return "", fmt.Errorf("internal error")
+		}
+		if count >= match.MaxActiveCorrespondenceGames {
+			return "", fmt.Errorf("too many active correspondence games")
+		}
+	}
+
+	state := match.NewCorrespondenceState(
+		utils.NewID("corr_"),
+		&match.PlayerData{UserID: userID, Username: usernames[userID]},
+		&match.PlayerData{UserID: req.OpponentID, Username: usernames[req.OpponentID]},
+		moveHours,
+	)
+
+	game := &dbpkg.CorrespondenceGame{
+		GameID:    state.MatchID,
+		PlayerXID: userID,
+		PlayerOID: req.OpponentID,
+	}
+	if err := applyCorrespondenceState(game, state); err != nil {
+		logger.Error("Correspondence state marshal failed: %v", err)
+		return "", fmt.Errorf("internal error")
+	}
+	if err := repo.CreateCorrespondenceGame(ctx, game); err != nil {
+		logger.Error("Correspondence game create failed: %v", err)
+		return "", fmt.Errorf("game creation failed")
+	}
+
+	logger.Info("Correspondence game %s created: %s vs %s", game.GameID, userID, req.OpponentID)
+	notifyCorrespondence(ctx, logger, nk, state, req.OpponentID, userID, match.NotificationCorrespondenceInvite, "New correspondence game")
+
+	return marshalResponse(toCorrespondenceGame(game, state), logger)
+}
+
+// RPCSubmitCorrespondenceMove validates and applies the caller's move, saves
+// the game, and notifies the opponent that it is their turn (or that the
+// game is over).
+func RPCSubmitCorrespondenceMove(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req CorrespondenceMoveRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.GameID == "" {
+		return "", fmt.Errorf("game_id required")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	game, state, err := loadCorrespondenceGame(ctx, repo, req.GameID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if expireCorrespondenceGame(ctx, logger, db, nk, repo, game, state) {
+		return "", fmt.Errorf("move deadline has passed")
+	}
+
+	if err := match.PlayCorrespondenceMove(state, userID, req.Position); err != nil {
+		return "", err
+	}
+
+	if err := saveCorrespondenceGame(ctx, repo, game, state); err != nil {
+		if errors.Is(err, dbpkg.ErrVersionConflict) {
+			return "", fmt.Errorf("game was updated, please retry")
+		}
+		logger.Error("Correspondence game save failed for %s: %v", game.GameID, err)
+		return "", fmt.Errorf("move failed")
+	}
+
+	opponentID := correspondenceOpponent(state, userID)
+	if state.GameOver {
+		match.RecordCorrespondenceResult(ctx, logger, db, nk, state)
+		notifyCorrespondence(ctx, logger, nk, state, opponentID, userID, match.NotificationCorrespondenceOver, "Correspondence game over")
+	} else {
+		notifyCorrespondence(ctx, logger, nk, state, opponentID, userID, match.NotificationCorrespondenceTurn, "Your move")
+	}
+
+	return marshalResponse(toCorrespondenceGame(game, state), logger)
+}
+
+// RPCGetCorrespondenceGame returns a single correspondence game the caller
+// is part of.
+func RPCGetCorrespondenceGame(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req CorrespondenceGetRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.GameID == "" {
+		return "", fmt.Errorf("game_id required")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	game, state, err := loadCorrespondenceGame(ctx, repo, req.GameID, userID)
+	if err != nil {
+		return "", err
+	}
+	expireCorrespondenceGame(ctx, logger, db, nk, repo, game, state)
+
+	return marshalResponse(toCorrespondenceGame(game, state), logger)
+}
+
+// RPCListCorrespondenceGames returns the caller's correspondence games,
+// most recently updated first.
+func RPCListCorrespondenceGames(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req CorrespondenceListRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+
+	repo := dbpkg.NewRepository(db)
+	games, err := repo.ListCorrespondenceGames(ctx, userID, req.IncludeFinished, correspondenceListLimit)
+	if err != nil {
+		logger.Error("Correspondence list failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := CorrespondenceListResponse{Games: []CorrespondenceGame{}}
+	for _, game := range games {
+		var state match.MatchState
+		if err := json.Unmarshal(game.State, &state); err != nil {
+			logger.Error("Corrupt correspondence state for %s: %v", game.GameID, err)
+			continue
+		}
+		expireCorrespondenceGame(ctx, logger, db, nk, repo, game, &state)
+		response.Games = append(response.Games, toCorrespondenceGame(game, &state))
+	}
+
+	return marshalResponse(response, logger)
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+func loadCorrespondenceGame(ctx context.Context, repo *dbpkg.Repository, gameID, userID string) (*dbpkg.CorrespondenceGame, *match.MatchState, error) {
+	game, err := repo.GetCorrespondenceGame(ctx, gameID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("game not found")
+		}
+		return nil, nil, fmt.Errorf("internal error")
+	}
+	if game.PlayerXID != userID && game.PlayerOID != userID {
+		return nil, nil, fmt.Errorf("game not found")
+	}
+
+	var state match.MatchState
+	if err := json.Unmarshal(game.State, &state); err != nil {
+		return nil, nil, fmt.Errorf("corrupt game data")
+	}
+	return game, &state, nil
+}
+
+// expireCorrespondenceGame ends a game whose move deadline has passed and
+// records the result. Games are expired lazily whenever they are read.
+func expireCorrespondenceGame(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, repo *dbpkg.Repository, game *dbpkg.CorrespondenceGame, state *match.MatchState) bool {
+	if !match.ExpireCorrespondenceTurn(state) {
+		return false
+	}
+
+	if err := saveCorrespondenceGame(ctx, repo, game, state); err != nil {
+		// Another request got there first; it owns recording the result.
+		if !errors.Is(err, dbpkg.ErrVersionConflict) {
+			logger.Error("Correspondence expiry save failed for %s: %v", game.GameID, err)
+		}
+		return true
+	}
+
+	logger.Info("Correspondence game %s expired — winner: %s", game.GameID, state.Winner)
+	match.RecordCorrespondenceResult(ctx, logger, db, nk, state)
+	for userID := range state.Players {
+		notifyCorrespondence(ctx, logger, nk, state, userID, "", match.NotificationCorrespondenceOver, "Correspondence game over")
+	}
+	return true
+}
+
+func saveCorrespondenceGame(ctx context.Context, repo *dbpkg.Repository, game *dbpkg.CorrespondenceGame, state *match.MatchState) error {
+	if err := applyCorrespondenceState(game, state); err != nil {
+		return err
+	}
+	return repo.UpdateCorrespondenceGame(ctx, game)
+}
+
+// applyCorrespondenceState copies the match state into the stored row.
+func applyCorrespondenceState(game *dbpkg.CorrespondenceGame, state *match.MatchState) error {
+	stateJSON, err := utils.JsonMarshal(state)
+	if err != nil {
+		return err
+	}
+
+	game.State = stateJSON
+	game.CurrentTurnID = state.CurrentTurnID
+	game.Status = dbpkg.CorrespondenceActive
+	game.MoveDeadline = time.Unix(state.TurnDeadline(), 0)
+	if state.GameOver {
+		game.Status = dbpkg.CorrespondenceFinished
+		game.CurrentTurnID = ""
+	}
+	return nil
+}
+
+func toCorrespondenceGame(game *dbpkg.CorrespondenceGame, state *match.MatchState) CorrespondenceGame {
+	deadline := int64(0)
+	if !state.GameOver {
+		deadline = state.TurnDeadline()
+	}
+	return CorrespondenceGame{
+		GameID:       game.GameID,
+		Status:       game.Status,
+		MoveDeadline: deadline,
+		State:        state,
+	}
+}
+
+func correspondenceOpponent(state *match.MatchState, userID string) string {
+	for id := range state.Players {
+		if id != userID {
+			return id
+		}
+	}
+	return ""
+}
+
+func notifyCorrespondence(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, state *match.MatchState, recipientID, senderID string, code int, subject string) {
+	content := map[string]interface{}{
+		"game_id":       state.MatchID,
+		"game_over":     state.GameOver,
+		"winner":        state.Winner,
+		"is_draw":       state.IsDraw,
+		"move_deadline": state.TurnDeadline(),
+	}
+	if err := nk.NotificationSend(ctx, recipientID, subject, content, code, senderID, true); err != nil {
+		logger.Warn("Correspondence notification to %s failed: %v", recipientID, err)
+	}
+}
@@ -4,24 +4,78 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
 
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
-	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
 	"github.com/prasanth-33460/tic-tac-toe/backend/match"
 )
 
-// RPCGetLeaderboard returns the top-10 entries from both the global wins
-// and win-streaks leaderboards.
+const (
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+
+	// maxLeaderboardFriends caps how many friends are looked up for a
+	// friends-only leaderboard.
+	maxLeaderboardFriends = 1000
+)
+
+// RPCGetLeaderboard returns one page of a leaderboard. The payload selects
+// the board, page size and cursor, and can centre the page on the caller
+// ("around me") or restrict it to the caller and their friends. The
+// caller's own record is always included, even when it is off the page.
+//
+// Without a leaderboard_id it returns the top-10 entries from both the
+// global wins and win-streaks leaderboards.
 func RPCGetLeaderboard(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	var req LeaderboardRequest
+	if payload != "" && payload != "{}" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+
+	if req.LeaderboardID == "" {
+		return topLeaderboards(logger, nk)
+	}
+
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if (req.AroundMe || req.FriendsOnly) && userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+	if req.Limit <= 0 || req.Limit > maxLeaderboardLimit {
+		req.Limit = defaultLeaderboardLimit
+	}
+
+	var (
+		page *LeaderboardPageResponse
+		err  error
+	)
+	switch {
+	case req.FriendsOnly:
+		page, err = friendsLeaderboardPage(ctx, nk, req, userID)
+	case req.AroundMe:
+		page, err = aroundMeLeaderboardPage(ctx, nk, req, userID)
+	default:
+		page, err = leaderboardPage(ctx, nk, req, userID)
+	}
+	if err != nil {
+		logger.Error("Leaderboard %s fetch failed: %v", req.LeaderboardID, err)
+		return "", fmt.Errorf("leaderboard fetch failed")
+	}
+
+	return marshalResponse(page, logger)
+}
+
+// topLeaderboards returns the top-10 of both overall boards.
+func topLeaderboards(logger runtime.Logger, nk runtime.NakamaModule) (string, error) {
 	response := LeaderboardResponse{
 		GlobalWins: []LeaderboardEntry{},
 		WinStreaks: []LeaderboardEntry{},
 	}
 
-	if err := dbpkg.EnsureLeaderboards(logger, nk); err != nil {
-		logger.Warn("Leaderboard ensure failed: %v", err)
-	}
-
 	serverCtx := context.Background()
 	const limit = 10
 
@@ -29,12 +83,7 @@ func RPCGetLeaderboard(ctx context.Context, logger runtime.Logger, db *sql.DB, n
 		logger.Error("Wins leaderboard fetch failed: %v", err)
 	} else {
 		for _, r := range records {
-			response.GlobalWins = append(response.GlobalWins, LeaderboardEntry{
-				UserID:   r.GetOwnerId(),
-				Username: r.GetUsername().GetValue(),
-				Score:    r.GetScore(),
-				Rank:     r.GetRank(),
-			})
+			response.GlobalWins = append(response.GlobalWins, toLeaderboardEntry(r))
 		}
 	}
 
@@ -42,15 +91,139 @@ func RPCGetLeaderboard(ctx context.Context, logger runtime.Logger, db *sql.DB, n
 		logger.Error("Streaks leaderboard fetch failed: %v", err)
 	} else {
 		for _, r := range records {
-			response.WinStreaks = append(response.WinStreaks, LeaderboardEntry{
-				UserID:   r.GetOwnerId(),
-				Username: r.GetUsername().GetValue(),
-				Score:    r.GetScore(),
-				Rank:     r.GetRank(),
-			})
+			response.WinStreaks = append(response.WinStreaks, toLeaderboardEntry(r))
 		}
 	}
 
 	b, _ := json.Marshal(response)
 	return string(b), nil
 }
+
+// leaderboardPage is a plain ranked page, starting from the top or from
+// the given cursor.
+func leaderboardPage(ctx context.Context, nk runtime.NakamaModule, req LeaderboardRequest, userID string) (*LeaderboardPageResponse, error) {
+	var ownerIDs []string
+	if userID != "" {
+		ownerIDs = []string{userID}
+	}
+
+	records, ownerRecords, next, prev, err := nk.LeaderboardRecordsList(ctx, req.LeaderboardID, ownerIDs, req.Limit, req.Cursor, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	page := newLeaderboardPage(req.LeaderboardID, records, next, prev)
+	page.Me = findOwnerRecord(ownerRecords, userID)
+	return page, nil
+}
+
+// aroundMeLeaderboardPage centres the page on the caller's record. Once the
+// client pages away with a cursor it behaves like a plain ranked page.
+func aroundMeLeaderboardPage(ctx context.Context, nk runtime.NakamaModule, req LeaderboardRequest, userID string) (*LeaderboardPageResponse, error) {
+	if req.Cursor != "" {
+		return leaderboardPage(ctx, nk, req, userID)
+	}
+
+	list, err := nk.LeaderboardRecordsHaystack(ctx, req.LeaderboardID, userID, req.Limit, "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	page := newLeaderboardPage(req.LeaderboardID, list.GetRecords(), list.GetNextCursor(), list.GetPrevCursor())
+	page.Me = findOwnerRecord(list.GetRecords(), userID)
+	return page, nil
+}
+
+// friendsLeaderboardPage ranks only the caller and their mutual friends.
+// Entries keep their global rank; the cursor is an offset into the list.
+func friendsLeaderboardPage(ctx context.Context, nk runtime.NakamaModule, req LeaderboardRequest, userID string) (*LeaderboardPageResponse, error) {
+	ownerIDs, err := friendIDs(ctx, nk, userID)
+	if err != nil {
+		return nil, err
+	}
+	ownerIDs = append(ownerIDs, userID)
+
+	_, ownerRecords, _, _, err := nk.LeaderboardRecordsList(ctx, req.LeaderboardID, ownerIDs, 1, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ownerRecords, func(i, j int) bool {
+		return ownerRecords[i].GetRank() < ownerRecords[j].GetRank()
+	})
+
+	offset := 0
+	if req.Cursor != "" {
+		if offset, err = strconv.Atoi(req.Cursor); err != nil || offset < 0 || offset > len(ownerRecords) {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+	}
+	end := min(offset+req.Limit, len(ownerRecords))
+
+	var next, prev string
+	if end < len(ownerRecords) {
+		next = strconv.Itoa(end)
+	}
+	if offset > 0 {
+		prev = strconv.Itoa(max(offset-req.Limit, 0))
+	}
+
+	page := newLeaderboardPage(req.LeaderboardID, ownerRecords[offset:end], next, prev)
+	page.Me = findOwnerRecord(ownerRecords, userID)
+	return page, nil
+}
+
+// friendIDs returns the user IDs of the caller's mutual friends.
+func friendIDs(ctx context.Context, nk runtime.NakamaModule, userID string) ([]string, error) {
+	state := int(api.Friend_FRIEND)
+	var ids []string
+	cursor := ""
+	for len(ids) < maxLeaderboardFriends {
+		friends, next, err := nk.FriendsList(ctx, userID, maxLeaderboardFriends-len(ids), &state, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range friends {
+			ids = append(ids, f.GetUser().GetId())
+		}
+		if next == "" || len(friends) == 0 {
+			break
+		}
+		cursor = next
+	}
+	return ids, nil
+}
+
+func newLeaderboardPage(leaderboardID string, records []*api.LeaderboardRecord, next, prev string) *LeaderboardPageResponse {
+	page := &LeaderboardPageResponse{
+		LeaderboardID: leaderboardID,
+		Records:       []LeaderboardEntry{},
+		NextCursor:    next,
+		PrevCursor:    prev,
+	}
+	for _, r := range records {
+		page.Records = append(page.Records, toLeaderboardEntry(r))
+	}
+	return page
+}
+
+func findOwnerRecord(records []*api.LeaderboardRecord, userID string) *LeaderboardEntry {
+	if userID == "" {
+		return nil
+	}
+	for _, r := range records {
+		if r.GetOwnerId() == userID {
+			entry := toLeaderboardEntry(r)
+			return &entry
+		}
+	}
+	return nil
+}
+
+func toLeaderboardEntry(r *api.LeaderboardRecord) LeaderboardEntry {
+	return LeaderboardEntry{
+		UserID:   r.GetOwnerId(),
+		Username: r.GetUsername().GetValue(),
+		Score:    r.GetScore(),
+		Rank:     r.GetRank(),
+	}
+}
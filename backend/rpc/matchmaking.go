@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"strings"
 
 	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
 	"github.com/prasanth-33460/tic-tac-toe/backend/match"
 )
 
@@ -18,7 +20,15 @@ func RPCFindMatch(ctx context.Context, logger runtime.Logger, db *sql.DB, nk run
 
 	logger.Info("Finding match — mode: %s, skill: %d", req.Mode, req.SkillLevel)
 
-	params := map[string]interface{}{"mode": req.Mode}
+	params, err := matchParams(req)
+	if err != nil {
+		return "", err
+	}
+	// A player left waiting is offered a bot; wagers need a human, and
+	// bots cannot play vanishing games.
+	if secs := match.BotFillWaitSecs(ctx); secs > 0 && req.Stake == 0 && req.Bot == "" && req.Mode != match.ModeVanishing {
+		params["bot_fill_secs"] = secs
+	}
 	matchID, err := nk.MatchCreate(ctx, "tictactoe", params)
 	if err != nil {
 		logger.Error("Match creation failed: %v", err)
@@ -45,9 +55,12 @@ func RPCFindMatch(ctx context.Context, logger runtime.Logger, db *sql.DB, nk run
 	}
 
 	return marshalResponse(map[string]interface{}{
-		"matchId":   matchID,
-		"shortCode": shortCode,
-		"mode":      req.Mode,
+		"matchId":     matchID,
+		"shortCode":   shortCode,
+		"mode":        req.Mode,
+		"stake":       req.Stake,
+		"emotes_only": req.EmotesOnly,
+		"casual":      req.Casual,
 	}, logger)
 }
 
@@ -57,7 +70,10 @@ func RPCCreateQuickMatch(ctx context.Context, logger runtime.Logger, db *sql.DB,
 
 	logger.Info("Quick match — mode: %s", req.Mode)
 
-	params := map[string]interface{}{"mode": req.Mode}
+	params, err := matchParams(req)
+	if err != nil {
+		return "", err
+	}
 	matchID, err := nk.MatchCreate(ctx, "tictactoe", params)
 	if err != nil {
 		logger.Error("Match creation failed: %v", err)
@@ -65,8 +81,11 @@ func RPCCreateQuickMatch(ctx context.Context, logger runtime.Logger, db *sql.DB,
 	}
 
 	return marshalResponse(map[string]interface{}{
-		"matchId": matchID,
-		"mode":    req.Mode,
+		"matchId":     matchID,
+		"mode":        req.Mode,
+		"stake":       req.Stake,
+		"emotes_only": req.EmotesOnly,
+		"casual":      req.Casual,
 	}, logger)
 }
 
@@ -77,6 +96,15 @@ func MatchmakerMatched(ctx context.Context, logger runtime.Logger, db *sql.DB, n
 		return "", fmt.Errorf("expected 2 players, got %d", len(entries))
 	}
 
+	blocked, err := dbpkg.NewRepository(db).IsBlockedEither(ctx, entries[0].GetPresence().GetUserId(), entries[1].GetPresence().GetUserId())
+	if err != nil {
+		logger.Error("Matchmaker block lookup failed: %v", err)
+		return "", err
+	}
+	if blocked {
+		return "", fmt.Errorf("matched players have blocked each other")
+	}
+
 	mode := match.ModeClassic
 	if props := entries[0].GetProperties(); props != nil {
 		if modeStr, ok := props["mode"].(string); ok {
@@ -94,6 +122,46 @@ func MatchmakerMatched(ctx context.Context, logger runtime.Logger, db *sql.DB, n
 	return matchID, nil
 }
 
+// MatchmakerOverride drops candidate matches that would pair players who
+// have blocked each other, so the matchmaker keeps looking for them.
+func MatchmakerOverride(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, candidateMatches [][]runtime.MatchmakerEntry) [][]runtime.MatchmakerEntry {
+	seen := make(map[string]bool)
+	var userIDs []string
+	for _, candidate := range candidateMatches {
+		for _, entry := range candidate {
+			if id := entry.GetPresence().GetUserId(); !seen[id] {
+				seen[id] = true
+				userIDs = append(userIDs, id)
+			}
+		}
+	}
+
+	blocks, err := dbpkg.NewRepository(db).BlocksAmong(ctx, userIDs)
+	if err != nil {
+		logger.Error("Matchmaker block lookup failed: %v", err)
+		return nil
+	}
+
+	matches := make([][]runtime.MatchmakerEntry, 0, len(candidateMatches))
+	for _, candidate := range candidateMatches {
+		if !hasBlockedPair(candidate, blocks) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+func hasBlockedPair(entries []runtime.MatchmakerEntry, blocks dbpkg.BlockSet) bool {
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			if blocks.Either(entries[i].GetPresence().GetUserId(), entries[j].GetPresence().GetUserId()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // RPCGetMatchIdByCode resolves a 6-digit short code to the full match ID.
 func RPCGetMatchIdByCode(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	var req struct {
@@ -192,6 +260,43 @@ func parseMatchRequest(payload string, logger runtime.Logger) MatchRequest {
 	return req
 }
 
+// matchParams builds the MatchCreate params for a match request. A stake
+// turns the match into a wagered one; joiners must agree to it. EmotesOnly
+// turns off free-text chat for the match. Casual matches are unrated and
+// allow hints. Bot seats the server's bot of that difficulty opposite the
+// creator; bot games are always casual.
+func matchParams(req MatchRequest) (map[string]interface{}, error) {
+	params := map[string]interface{}{"mode": req.Mode}
+	if req.Stake < 0 || req.Stake > match.MaxWagerStake {
+		return nil, fmt.Errorf("stake must be between 0 and %d", match.MaxWagerStake)
+	}
+	if req.Stake > 0 {
+		params["stake"] = req.Stake
+	}
+	if req.EmotesOnly {
+		params["emotes_only"] = true
+	}
+	if req.Casual {
+		if req.Stake > 0 {
+			return nil, fmt.Errorf("wagered matches are always rated")
+		}
+		params["casual"] = true
+	}
+	if req.Bot != "" {
+		if !match.IsBotDifficulty(req.Bot) {
+			return nil, fmt.Errorf("bot must be one of %s", strings.Join(match.BotDifficulties, ", "))
+		}
+		if req.Stake > 0 {
+			return nil, fmt.Errorf("bot games cannot be wagered")
+		}
+		if req.Mode == match.ModeVanishing {
+			return nil, fmt.Errorf("bot games are not available in vanishing mode")
+		}
+		params["bot"] = req.Bot
+	}
+	return params, nil
+}
+
 func generateShortCode(nk runtime.NakamaModule, ctx context.Context, logger runtime.Logger) string {
 	for i := 0; i < 10; i++ {
 		code := fmt.Sprintf("%06d", rand.Intn(1000000))
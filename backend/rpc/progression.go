@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+)
+
+// RPCGetProgression returns a player's XP, level and the current daily and
+// weekly quests with their progress. Defaults to the caller.
+func RPCGetProgression(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	var req ProgressionRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+	if req.UserID == "" {
+		req.UserID, _ = ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	}
+	if req.UserID == "" {
+		return "", fmt.Errorf("user_id required")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	xp, err := repo.GetXP(ctx, req.UserID)
+	if err != nil {
+		logger.Error("XP fetch failed for %s: %v", req.UserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	quests := match.ActiveQuests(time.Now())
+	var periodKeys []string
+	seen := make(map[string]bool)
+	for _, q := range quests {
+		if !seen[q.PeriodKey] {
+			seen[q.PeriodKey] = true
+			periodKeys = append(periodKeys, q.PeriodKey)
+		}
+	}
+
+	progress, err := repo.GetQuestProgress(ctx, req.UserID, periodKeys)
+	if err != nil {
+		logger.Error("Quest progress fetch failed for %s: %v", req.UserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	level, next := match.LevelForXP(xp)
+	response := ProgressionResponse{
+		UserID:      req.UserID,
+		XP:          xp,
+		Level:       level,
+		NextLevelXP: next,
+		Quests:      make([]QuestEntry, 0, len(quests)),
+	}
+	for _, q := range quests {
+		p := progress[q.ID]
+		response.Quests = append(response.Quests, QuestEntry{
+			ID:        q.ID,
+			Title:     q.Title,
+			Period:    q.Period,
+			Mode:      q.Mode,
+			Progress:  p.Progress,
+			Target:    q.Target,
+			RewardXP:  q.RewardXP,
+			Completed: p.Progress >= q.Target,
+			Claimed:   p.Claimed,
+			ExpiresAt: q.ExpiresAt.Unix(),
+		})
+	}
+
+	return marshalResponse(response, logger)
+}
+
+// RPCClaimQuestReward credits the XP reward of a completed quest in the
+// current rotation. Each quest can be claimed once per period.
+func RPCClaimQuestReward(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req ClaimQuestRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil || req.QuestID == "" {
+		return "", fmt.Errorf("invalid request")
+	}
+
+	quest, ok := match.FindActiveQuest(req.QuestID, time.Now())
+	if !ok {
+		return "", fmt.Errorf("quest not active")
+	}
+
+	xp, err := dbpkg.NewRepository(db).ClaimQuest(ctx, userID, quest.ID, quest.PeriodKey, quest.Target, quest.RewardXP)
+	switch {
+	case errors.Is(err, dbpkg.ErrQuestIncomplete):
+		return "", fmt.Errorf("quest not complete")
+	case errors.Is(err, dbpkg.ErrQuestAlreadyClaimed):
+		return "", fmt.Errorf("quest already claimed")
+	case err != nil:
+		logger.Error("Quest claim failed for %s/%s: %v", userID, quest.ID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	logger.Info("Quest %s claimed by %s (+%d XP)", quest.ID, userID, quest.RewardXP)
+
+	level, next := match.LevelForXP(xp)
+	return marshalResponse(ClaimQuestResponse{
+		QuestID:     quest.ID,
+		RewardXP:    quest.RewardXP,
+		XP:          xp,
+		Level:       level,
+		NextLevelXP: next,
+	}, logger)
+}
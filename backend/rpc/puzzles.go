@@ -0,0 +1,141 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+)
+
+// RPCGetDailyPuzzle returns today's puzzle and the caller's progress on it.
+// The solve clock starts on the first call.
+func RPCGetDailyPuzzle(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	puzzle, err := match.DailyPuzzle(match.PuzzleDate(time.Now()))
+	if err != nil {
+		logger.Error("Daily puzzle generation failed: %v", err)
+		return "", fmt.Errorf("puzzle unavailable")
+	}
+
+	attempt, err := dbpkg.NewRepository(db).StartPuzzleAttempt(ctx, userID, puzzle.Date)
+	if err != nil {
+		logger.Error("Puzzle attempt start failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	var moves []int
+	if err := json.Unmarshal(attempt.Moves, &moves); err != nil {
+		moves = nil
+	}
+
+	return marshalResponse(PuzzleResponse{
+		Puzzle:           puzzle,
+		Current:          puzzle.Replay(moves),
+		CurrentNextBoard: puzzle.NextBoardAfter(moves),
+		Moves:            nonNilInts(moves),
+		Attempts:         attempt.Attempts,
+		Solved:           attempt.Solved,
+		SolveMs:          attempt.SolveMs,
+	}, logger)
+}
+
+// RPCSubmitPuzzleMove checks the caller's next move in today's puzzle. A
+// wrong move counts as a failed try and resets the puzzle; a correct one
+// is answered with the engine's defence. Solving writes tries and solve
+// time to the daily puzzle leaderboard.
+func RPCSubmitPuzzleMove(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req PuzzleMoveRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	today := match.PuzzleDate(time.Now())
+	if req.Date != "" && req.Date != today {
+		return "", fmt.Errorf("puzzle has expired")
+	}
+
+	puzzle, err := match.DailyPuzzle(today)
+	if err != nil {
+		logger.Error("Daily puzzle generation failed: %v", err)
+		return "", fmt.Errorf("puzzle unavailable")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	attempt, err := repo.StartPuzzleAttempt(ctx, userID, today)
+	if err != nil {
+		logger.Error("Puzzle attempt load failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if attempt.Solved {
+		return "", fmt.Errorf("puzzle already solved")
+	}
+	var moves []int
+	if err := json.Unmarshal(attempt.Moves, &moves); err != nil {
+		moves = nil
+	}
+
+	result, err := match.CheckPuzzleMove(puzzle, moves, req.Position)
+	if err != nil {
+		return "", err
+	}
+
+	response := PuzzleMoveResponse{PuzzleMoveResult: *result, Attempts: attempt.Attempts}
+	switch {
+	case !result.Correct:
+		moves = nil
+		response.Attempts++
+	case result.Solved:
+		moves = append(moves, req.Position)
+	default:
+		moves = append(moves, req.Position, result.Reply)
+	}
+	movesJSON, _ := json.Marshal(nonNilInts(moves))
+
+	if !result.Solved {
+		if _, err := repo.UpdatePuzzleProgress(ctx, userID, today, movesJSON, response.Attempts); err != nil {
+			logger.Error("Puzzle progress update failed for %s: %v", userID, err)
+			return "", fmt.Errorf("internal error")
+		}
+		response.Moves = nonNilInts(moves)
+		return marshalResponse(response, logger)
+	}
+
+	solveMs, marked, err := repo.MarkPuzzleSolved(ctx, userID, today, movesJSON)
+	if err != nil {
+		logger.Error("Puzzle solve update failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if !marked {
+		return "", fmt.Errorf("puzzle already solved")
+	}
+
+	// Fewer tries rank first, then the faster solve.
+	username, _ := ctx.Value(runtime.RUNTIME_CTX_USERNAME).(string)
+	tries := int64(response.Attempts + 1)
+	if _, err := nk.LeaderboardRecordWrite(ctx, match.LeaderboardPuzzle, userID, username, tries, solveMs, nil, nil); err != nil {
+		logger.Error("Puzzle leaderboard write failed for %s: %v", userID, err)
+	}
+
+	response.Moves = nonNilInts(moves)
+	response.SolveMs = solveMs
+	return marshalResponse(response, logger)
+}
+
+func nonNilInts(v []int) []int {
+	if v == nil {
+		return []int{}
+	}
+	return v
+}
@@ -0,0 +1,309 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
+)
+
+// Report categories a player can choose from.
+var reportCategories = map[string]bool{
+	"cheating": true,
+	"abuse":    true,
+	"spam":     true,
+	"stalling": true,
+	"other":    true,
+}
+
+const (
+	maxReportDetails = 1000
+	reportQueueLimit = 100
+
+	// A player is flagged as a repeat offender once this many different
+	// players report them within the window, or once this many reports
+	// against them have been acted on.
+	offenderReporters = 3
+	offenderWindow    = 30 * 24 * time.Hour
+	offenderActioned  = 2
+)
+
+// RPCReportPlayer files a report against another player in a live or
+// finished match. The match's chat transcript and move list are attached
+// automatically.
+func RPCReportPlayer(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req ReportRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.MatchID == "" {
+		return "", fmt.Errorf("match_id required")
+	}
+	if !reportCategories[req.Category] {
+		return "", fmt.Errorf("category must be one of cheating, abuse, spam, stalling, other")
+	}
+	req.Details = strings.TrimSpace(req.Details)
+	if len(req.Details) > maxReportDetails {
+		return "", fmt.Errorf("details must be at most %d characters", maxReportDetails)
+	}
+
+	gameIndex := dbpkg.LatestGame
+	if req.Game != nil {
+		if *req.Game < 0 {
+			return "", fmt.Errorf("invalid game")
+		}
+		gameIndex = *req.Game
+	}
+
+	repo := dbpkg.NewRepository(db)
+
+	players, moves, err := reportEvidence(ctx, nk, repo, req.MatchID, gameIndex, userID)
+	if err != nil {
+		logger.Warn("Report evidence unavailable for %s in %s: %v", userID, req.MatchID, err)
+		return "", fmt.Errorf("match not found")
+	}
+
+	reportedID := req.ReportedUserID
+	if reportedID == "" {
+		for _, id := range players {
+			if id != userID {
+				reportedID = id
+			}
+		}
+	}
+	if reportedID == "" || reportedID == userID || !slices.Contains(players, reportedID) {
+		return "", fmt.Errorf("reported player was not in this match")
+	}
+
+	messages, err := repo.GetChatTranscript(ctx, req.MatchID, maxTranscriptLimit)
+	if err != nil {
+		logger.Error("Transcript fetch failed for %s: %v", req.MatchID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	transcript := make([]TranscriptEntry, 0, len(messages))
+	for _, m := range messages {
+		transcript = append(transcript, TranscriptEntry{
+			UserID:    m.UserID,
+			Username:  m.Username,
+			Message:   m.Message,
+			Timestamp: m.CreatedAt.Unix(),
+		})
+	}
+	transcriptJSON, _ := json.Marshal(transcript)
+
+	report := &dbpkg.Report{
+		ReportID:   utils.NewID("report_"),
+		ReporterID: userID,
+		ReportedID: reportedID,
+		MatchID:    req.MatchID,
+		Category:   req.Category,
+		Details:    req.Details,
+		Transcript: transcriptJSON,
+		Moves:      moves,
+	}
+	created, err := repo.CreateReport(ctx, report)
+	if err != nil {
+		logger.Error("Report insert failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if !created {
+		return "", fmt.Errorf("you already reported this player for this match")
+	}
+
+	if _, err := repo.RefreshOffenderFlag(ctx, reportedID, offenderWindow, offenderReporters, offenderActioned); err != nil {
+		logger.Warn("Offender flag refresh failed for %s: %v", reportedID, err)
+	}
+
+	logger.Info("Player %s reported %s in %s for %s", userID, reportedID, req.MatchID, req.Category)
+	return marshalResponse(map[string]interface{}{"success": true, "report_id": report.ReportID}, logger)
+}
+
+// reportEvidence returns the players and JSON move list of one game of the
+// match. A live match answers for the game in progress; earlier games, and
+// every game of a finished match, come from the recorded match history.
+// Either way the reporter must have played in it.
+func reportEvidence(ctx context.Context, nk runtime.NakamaModule, repo *dbpkg.Repository, matchID string, gameIndex int, userID string) ([]string, []byte, error) {
+	signalData, err := json.Marshal(map[string]string{
+		"type":   "report_snapshot",
+		"userId": userID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if result, err := nk.MatchSignal(ctx, matchID, string(signalData)); err == nil && !strings.HasPrefix(result, "error:") {
+		var snapshot match.ReportSnapshot
+		if err := json.Unmarshal([]byte(result), &snapshot); err != nil {
+			return nil, nil, err
+		}
+		if gameIndex == dbpkg.LatestGame || gameIndex == snapshot.GameIndex {
+			moves, _ := json.Marshal(snapshot.Moves)
+			return snapshot.Players, moves, nil
+		}
+	}
+
+	rec, err := repo.GetMatchRecord(ctx, matchID, gameIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	var players []string
+	if err := json.Unmarshal(rec.Players, &players); err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(players, userID) {
+		return nil, nil, fmt.Errorf("not a participant")
+	}
+	return players, rec.Moves, nil
+}
+
+// RPCListReports returns the moderation queue for one status (open by
+// default). Reports against flagged repeat offenders come first.
+func RPCListReports(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	var req ListReportsRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+	switch req.Status {
+	case "":
+		req.Status = dbpkg.ReportOpen
+	case dbpkg.ReportOpen, dbpkg.ReportClaimed, dbpkg.ReportResolved:
+	default:
+		return "", fmt.Errorf("status must be open, claimed or resolved")
+	}
+	if req.Limit <= 0 || req.Limit > reportQueueLimit {
+		req.Limit = reportQueueLimit
+	}
+
+	reports, err := dbpkg.NewRepository(db).ListReports(ctx, req.Status, req.Limit)
+	if err != nil {
+		logger.Error("Report queue fetch failed: %v", err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := ReportListResponse{Reports: make([]ReportResponse, 0, len(reports))}
+	for _, r := range reports {
+		response.Reports = append(response.Reports, reportResponse(r))
+	}
+	return marshalResponse(response, logger)
+}
+
+// RPCClaimReport assigns an open report to a moderator so two moderators
+// do not work the same report.
+func RPCClaimReport(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	var req ClaimReportRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.ReportID == "" || req.Moderator == "" {
+		return "", fmt.Errorf("report_id and moderator required")
+	}
+
+	claimed, err := dbpkg.NewRepository(db).ClaimReport(ctx, req.ReportID, req.Moderator)
+	if err != nil {
+		logger.Error("Report claim failed for %s: %v", req.ReportID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if !claimed {
+		return "", fmt.Errorf("report is not open")
+	}
+	return marshalResponse(map[string]interface{}{"success": true, "report_id": req.ReportID}, logger)
+}
+
+// RPCResolveReport closes a claimed report, optionally banning or chat
+// banning the reported player.
+func RPCResolveReport(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	var req ResolveReportRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return "", fmt.Errorf("invalid request")
+	}
+	if req.ReportID == "" || req.Moderator == "" {
+		return "", fmt.Errorf("report_id and moderator required")
+	}
+	if req.Action == "" {
+		req.Action = dbpkg.ReportActionNone
+	}
+	if req.Action != dbpkg.ReportActionNone && req.Action != dbpkg.ReportActionChatBan && req.Action != dbpkg.ReportActionBan {
+		return "", fmt.Errorf("action must be none, chat_ban or ban")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	report, err := repo.GetReport(ctx, req.ReportID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("report not found")
+	}
+	if err != nil {
+		logger.Error("Report lookup failed for %s: %v", req.ReportID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	resolved, err := repo.ResolveReport(ctx, req.ReportID, req.Moderator, req.Action, req.Resolution)
+	if err != nil {
+		logger.Error("Report resolve failed for %s (action %s): %v", req.ReportID, req.Action, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if !resolved {
+		return "", fmt.Errorf("report is not claimed by this moderator")
+	}
+	if req.Action == dbpkg.ReportActionChatBan {
+		signalChatBan(ctx, logger, nk, report.ReportedID, true)
+	}
+
+	flagged, err := repo.RefreshOffenderFlag(ctx, report.ReportedID, offenderWindow, offenderReporters, offenderActioned)
+	if err != nil {
+		logger.Warn("Offender flag refresh failed for %s: %v", report.ReportedID, err)
+	}
+
+	logger.Info("Report %s resolved by %s — action: %s", req.ReportID, req.Moderator, req.Action)
+	return marshalResponse(map[string]interface{}{
+		"success":          true,
+		"report_id":        req.ReportID,
+		"action":           req.Action,
+		"reported_flagged": flagged,
+	}, logger)
+}
+
+func reportResponse(r *dbpkg.Report) ReportResponse {
+	return ReportResponse{
+		ReportID:        r.ReportID,
+		ReporterID:      r.ReporterID,
+		ReportedID:      r.ReportedID,
+		ReportedFlagged: r.ReportedFlagged,
+		MatchID:         r.MatchID,
+		Category:        r.Category,
+		Details:         r.Details,
+		Transcript:      json.RawMessage(r.Transcript),
+		Moves:           json.RawMessage(r.Moves),
+		Status:          r.Status,
+		ClaimedBy:       r.ClaimedBy,
+		Action:          r.Action,
+		Resolution:      r.Resolution,
+		CreatedAt:       r.CreatedAt.Unix(),
+	}
+}
@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+	"github.com/prasanth-33460/tic-tac-toe/backend/utils"
+)
+
+const (
+	defaultSeasonsReturned = 6
+	maxSeasonsReturned     = 24
+	defaultSeasonStandings = 10
+)
+
+// SeasonLeaderboardReset is the Nakama leaderboard reset hook. When a
+// seasonal board resets it archives the final standings and rewards of the
+// season that just ended and notifies the rewarded players.
+func SeasonLeaderboardReset(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, leaderboard *api.Leaderboard, reset int64) error {
+	if !dbpkg.IsSeasonLeaderboard(leaderboard.GetId()) {
+		return nil
+	}
+
+	seasonStart, err := nk.CronPrev(dbpkg.SeasonResetSchedule, reset-1)
+	if err != nil {
+		logger.Warn("Season start lookup failed for %s: %v", leaderboard.GetId(), err)
+		seasonStart = int64(leaderboard.GetPrevReset())
+	}
+	start := time.Unix(seasonStart, 0).UTC()
+	end := time.Unix(reset, 0).UTC()
+	seasonID := start.Format("2006-01")
+
+	var standings []dbpkg.SeasonStanding
+	cursor := ""
+	for len(standings) < match.SeasonArchiveLimit {
+		records, _, next, _, err := nk.LeaderboardRecordsList(ctx, leaderboard.GetId(), nil, match.SeasonArchiveLimit-len(standings), cursor, reset)
+		if err != nil {
+			logger.Error("Season standings fetch failed for %s: %v", leaderboard.GetId(), err)
+			return err
+		}
+		for _, r := range records {
+			standings = append(standings, dbpkg.SeasonStanding{
+				LeaderboardID: leaderboard.GetId(),
+				SeasonID:      seasonID,
+				UserID:        r.GetOwnerId(),
+				Username:      r.GetUsername().GetValue(),
+				Rank:          r.GetRank(),
+				Score:         r.GetScore(),
+				Reward:        seasonReward(r.GetRank()),
+				SeasonStart:   start,
+				SeasonEnd:     end,
+			})
+		}
+		if next == "" || len(records) == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	repo := dbpkg.NewRepository(db)
+	if err := repo.ArchiveSeasonStandings(ctx, standings); err != nil {
+		logger.Error("Season archive failed for %s: %v", leaderboard.GetId(), err)
+		return err
+	}
+	logger.Info("Archived season %s of %s (%d players)", seasonID, leaderboard.GetId(), len(standings))
+
+	for _, s := range standings {
+		if s.Reward == "" {
+			continue
+		}
+		content := map[string]interface{}{
+			"leaderboard_id": s.LeaderboardID,
+			"season_id":      s.SeasonID,
+			"rank":           s.Rank,
+			"reward":         s.Reward,
+		}
+		if err := nk.NotificationSend(ctx, s.UserID, "Season reward", content, match.NotificationSeasonReward, "", true); err != nil {
+			logger.Warn("Season reward notification to %s failed: %v", s.UserID, err)
+		}
+	}
+	return nil
+}
+
+// RPCGetSeasonHistory returns the final standings of past seasons for one
+// seasonal board, newest first, including the caller's own placing.
+func RPCGetSeasonHistory(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+
+	var req SeasonHistoryRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+	if req.Leaderboard == "" {
+		req.Leaderboard = match.LeaderboardGlobalWins
+	}
+	if req.Leaderboard != match.LeaderboardGlobalWins && req.Leaderboard != match.LeaderboardWinStreaks {
+		return "", fmt.Errorf("unknown leaderboard: %s", req.Leaderboard)
+	}
+	if req.Mode == "" {
+		req.Mode = match.ModeClassic
+	}
+	if !utils.ContainsString(dbpkg.LeaderboardModes, req.Mode) {
+		return "", fmt.Errorf("unknown mode: %s", req.Mode)
+	}
+	if req.Seasons <= 0 || req.Seasons > maxSeasonsReturned {
+		req.Seasons = defaultSeasonsReturned
+	}
+	if req.Limit <= 0 || req.Limit > match.SeasonArchiveLimit {
+		req.Limit = defaultSeasonStandings
+	}
+
+	leaderboardID := dbpkg.SeasonLeaderboardID(req.Leaderboard, req.Mode)
+	repo := dbpkg.NewRepository(db)
+
+	seasons, err := repo.ListSeasons(ctx, leaderboardID, req.Seasons)
+	if err != nil {
+		logger.Error("Season list failed for %s: %v", leaderboardID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := SeasonHistoryResponse{LeaderboardID: leaderboardID, Seasons: []SeasonResult{}}
+	for _, season := range seasons {
+		result := SeasonResult{
+			SeasonID:    season.SeasonID,
+			SeasonStart: season.SeasonStart.Unix(),
+			SeasonEnd:   season.SeasonEnd.Unix(),
+			Standings:   []SeasonEntry{},
+		}
+
+		standings, err := repo.GetSeasonStandings(ctx, leaderboardID, season.SeasonID, req.Limit)
+		if err != nil {
+			logger.Error("Season standings fetch failed for %s/%s: %v", leaderboardID, season.SeasonID, err)
+			return "", fmt.Errorf("internal error")
+		}
+		for i := range standings {
+			result.Standings = append(result.Standings, toSeasonEntry(&standings[i]))
+		}
+
+		if userID != "" {
+			if me, err := repo.GetSeasonStanding(ctx, leaderboardID, season.SeasonID, userID); err == nil {
+				entry := toSeasonEntry(me)
+				result.Me = &entry
+			}
+		}
+
+		response.Seasons = append(response.Seasons, result)
+	}
+
+	return marshalResponse(response, logger)
+}
+
+// seasonReward returns the reward tier earned by a final rank, if any.
+func seasonReward(rank int64) string {
+	switch {
+	case rank == 1:
+		return "gold"
+	case rank == 2:
+		return "silver"
+	case rank == 3:
+		return "bronze"
+	case rank <= 10:
+		return "top_10"
+	case rank <= int64(match.SeasonArchiveLimit):
+		return "top_100"
+	default:
+		return ""
+	}
+}
+
+func toSeasonEntry(s *dbpkg.SeasonStanding) SeasonEntry {
+	return SeasonEntry{
+		UserID:   s.UserID,
+		Username: s.Username,
+		Rank:     s.Rank,
+		Score:    s.Score,
+		Reward:   s.Reward,
+	}
+}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+)
+
+// RPCGetPlayerStats returns a player's lifetime totals, current and best
+// win streak, rating, and per-mode breakdown. Defaults to the caller.
+// Registered bots get their bot record instead.
+func RPCGetPlayerStats(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	var req PlayerStatsRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+	if req.UserID == "" {
+		req.UserID, _ = ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	}
+	if req.UserID == "" {
+		return "", fmt.Errorf("user_id required")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	bot, err := repo.GetBotAccount(ctx, req.UserID)
+	if err == nil {
+		return marshalResponse(PlayerStatsResponse{
+			UserID:      bot.UserID,
+			Wins:        bot.Wins,
+			Losses:      bot.Losses,
+			Draws:       bot.Draws,
+			SkillRating: bot.LadderRating,
+			Modes:       []ModeStatsEntry{},
+			IsBot:       true,
+		}, logger)
+	}
+	if err != sql.ErrNoRows {
+		logger.Error("Bot lookup failed for %s: %v", req.UserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	stats, err := repo.GetPlayerStats(ctx, req.UserID)
+	if err != nil {
+		logger.Error("Stats fetch failed for %s: %v", req.UserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	modes, err := repo.GetModeStats(ctx, req.UserID)
+	if err != nil {
+		logger.Error("Mode stats fetch failed for %s: %v", req.UserID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := PlayerStatsResponse{
+		UserID:        stats.UserID,
+		Wins:          stats.Wins,
+		Losses:        stats.Losses,
+		Draws:         stats.Draws,
+		CurrentStreak: stats.CurrentStreak,
+		BestStreak:    stats.BestStreak,
+		SkillRating:   stats.SkillRating,
+		Abandons:      stats.Abandons,
+		Modes:         []ModeStatsEntry{},
+	}
+	for _, m := range modes {
+		response.Modes = append(response.Modes, ModeStatsEntry{
+			Mode:   m.Mode,
+			Wins:   m.Wins,
+			Losses: m.Losses,
+			Draws:  m.Draws,
+		})
+	}
+
+	return marshalResponse(response, logger)
+}
@@ -0,0 +1,153 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+)
+
+// RPCGetStoreCatalogue returns every cosmetic item with the caller's
+// ownership and equipped state.
+func RPCGetStoreCatalogue(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	inv, _, err := match.LoadInventory(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Inventory fetch failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	coins, err := match.WalletBalance(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Wallet fetch failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := CatalogueResponse{
+		Coins: coins,
+		Items: make([]CatalogueItem, 0, len(match.Cosmetics)),
+	}
+	for _, item := range match.Cosmetics {
+		response.Items = append(response.Items, CatalogueItem{
+			CosmeticItem: item,
+			Owned:        inv.Owns(item),
+			Equipped:     inv.EquippedItem(item.Slot) == item.ID,
+		})
+	}
+
+	return marshalResponse(response, logger)
+}
+
+// RPCPurchaseItem buys a cosmetic with coins. The debit and the inventory
+// write happen in one atomic update, so a player is never charged without
+// receiving the item.
+func RPCPurchaseItem(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	item, err := parseItemRequest(payload)
+	if err != nil {
+		return "", err
+	}
+
+	inv, version, err := match.LoadInventory(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Inventory fetch failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if inv.Owns(item) {
+		return "", fmt.Errorf("item already owned")
+	}
+
+	inv.Owned = append(inv.Owned, item.ID)
+	write, err := match.InventoryWrite(userID, inv, version)
+	if err != nil {
+		logger.Error("Inventory encode failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	debit := &runtime.WalletUpdate{
+		UserID:    userID,
+		Changeset: map[string]int64{match.WalletCurrency: -item.Price},
+		Metadata:  map[string]interface{}{"reason": match.LedgerPurchase, "item_id": item.ID},
+	}
+
+	_, results, err := nk.MultiUpdate(ctx, nil, []*runtime.StorageWrite{write}, nil, []*runtime.WalletUpdate{debit}, true)
+	if err != nil {
+		var negative *runtime.WalletNegativeError
+		if errors.As(err, &negative) {
+			return "", fmt.Errorf("insufficient coins")
+		}
+		logger.Warn("Purchase of %s by %s failed: %v", item.ID, userID, err)
+		return "", fmt.Errorf("purchase failed, please retry")
+	}
+
+	logger.Info("Item purchased: %s by %s for %d", item.ID, userID, item.Price)
+
+	response := InventoryResponse{Owned: inv.Owned, Equipped: inv.Equipped}
+	if len(results) > 0 {
+		response.Coins = results[0].Updated[match.WalletCurrency]
+	}
+	return marshalResponse(response, logger)
+}
+
+// RPCEquipItem equips an owned cosmetic in its slot. It shows up in the
+// player's next match.
+func RPCEquipItem(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	item, err := parseItemRequest(payload)
+	if err != nil {
+		return "", err
+	}
+
+	inv, version, err := match.LoadInventory(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Inventory fetch failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if !inv.Owns(item) {
+		return "", fmt.Errorf("item not owned")
+	}
+
+	inv.Equipped[item.Slot] = item.ID
+	write, err := match.InventoryWrite(userID, inv, version)
+	if err != nil {
+		logger.Error("Inventory encode failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+	if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{write}); err != nil {
+		logger.Warn("Equip of %s by %s failed: %v", item.ID, userID, err)
+		return "", fmt.Errorf("equip failed, please retry")
+	}
+
+	coins, err := match.WalletBalance(ctx, nk, userID)
+	if err != nil {
+		logger.Warn("Wallet fetch failed for %s: %v", userID, err)
+	}
+	return marshalResponse(InventoryResponse{Owned: inv.Owned, Equipped: inv.Equipped, Coins: coins}, logger)
+}
+
+func parseItemRequest(payload string) (match.CosmeticItem, error) {
+	var req ItemRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil || req.ItemID == "" {
+		return match.CosmeticItem{}, fmt.Errorf("invalid request")
+	}
+
+	item, ok := match.FindCosmetic(req.ItemID)
+	if !ok {
+		return match.CosmeticItem{}, fmt.Errorf("unknown item")
+	}
+	return item, nil
+}
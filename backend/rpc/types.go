@@ -1,5 +1,11 @@
 package rpc
 
+import (
+	"encoding/json"
+
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+)
+
 // MatchRequest is the payload for match creation RPCs.
 type MatchRequest struct {
 	Mode        string            `json:"mode"`
@@ -7,6 +13,10 @@ type MatchRequest struct {
 	Preferences map[string]string `json:"preferences"`
 	RatingRange int               `json:"rating_range"`
 	Metadata    map[string]string `json:"metadata"`
+	Stake       int64             `json:"stake"`
+	EmotesOnly  bool              `json:"emotes_only"`
+	Casual      bool              `json:"casual"`
+	Bot         string            `json:"bot"`
 }
 
 // LeaderboardEntry is a single row in a leaderboard.
@@ -17,6 +27,27 @@ type LeaderboardEntry struct {
 	Rank     int64  `json:"rank"`
 }
 
+// LeaderboardRequest selects a page of one leaderboard. AroundMe centres the
+// page on the caller; FriendsOnly restricts it to the caller and their
+// friends.
+type LeaderboardRequest struct {
+	LeaderboardID string `json:"leaderboard_id"`
+	Limit         int    `json:"limit"`
+	Cursor        string `json:"cursor"`
+	AroundMe      bool   `json:"around_me"`
+	FriendsOnly   bool   `json:"friends_only"`
+}
+
+// LeaderboardPageResponse is one page of a leaderboard plus the caller's
+// own record, which may fall outside the page.
+type LeaderboardPageResponse struct {
+	LeaderboardID string             `json:"leaderboard_id"`
+	Records       []LeaderboardEntry `json:"records"`
+	NextCursor    string             `json:"next_cursor,omitempty"`
+	PrevCursor    string             `json:"prev_cursor,omitempty"`
+	Me            *LeaderboardEntry  `json:"me,omitempty"`
+}
+
 // LeaderboardResponse wraps both leaderboard tables.
 type LeaderboardResponse struct {
 	GlobalWins []LeaderboardEntry `json:"global_wins"`
@@ -45,3 +76,488 @@ type RematchResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+// CorrespondenceCreateRequest is the payload for starting a correspondence game.
+type CorrespondenceCreateRequest struct {
+	OpponentID string `json:"opponent_id"`
+	MoveHours  int    `json:"move_hours"`
+}
+
+// CorrespondenceMoveRequest is the payload for submitting a correspondence move.
+type CorrespondenceMoveRequest struct {
+	GameID   string `json:"game_id"`
+	Position int    `json:"position"`
+}
+
+// CorrespondenceGetRequest identifies a single correspondence game.
+type CorrespondenceGetRequest struct {
+	GameID string `json:"game_id"`
+}
+
+// CorrespondenceListRequest filters the caller's correspondence games.
+type CorrespondenceListRequest struct {
+	IncludeFinished bool `json:"include_finished"`
+}
+
+// CorrespondenceGame describes one correspondence game to a participant.
+type CorrespondenceGame struct {
+	GameID       string            `json:"game_id"`
+	Status       string            `json:"status"`
+	MoveDeadline int64             `json:"move_deadline"`
+	State        *match.MatchState `json:"state"`
+}
+
+// CorrespondenceListResponse wraps the caller's correspondence games.
+type CorrespondenceListResponse struct {
+	Games []CorrespondenceGame `json:"games"`
+}
+
+// ArenaCreateRequest is the payload for starting an arena event.
+type ArenaCreateRequest struct {
+	Mode            string `json:"mode"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+// ArenaRequest identifies an arena.
+type ArenaRequest struct {
+	ArenaID string `json:"arena_id"`
+}
+
+// ArenaStanding is the caller's own progress in an arena.
+type ArenaStanding struct {
+	Status      string `json:"status"`
+	MatchID     string `json:"match_id,omitempty"`
+	Points      int    `json:"points"`
+	Streak      int    `json:"streak"`
+	GamesPlayed int    `json:"games_played"`
+}
+
+// ArenaResponse describes an arena and its live standings.
+type ArenaResponse struct {
+	ArenaID       string             `json:"arena_id"`
+	Mode          string             `json:"mode"`
+	StartsAt      int64              `json:"starts_at"`
+	EndsAt        int64              `json:"ends_at"`
+	Running       bool               `json:"running"`
+	LeaderboardID string             `json:"leaderboard_id"`
+	Standings     []LeaderboardEntry `json:"standings"`
+	Me            *ArenaStanding     `json:"me,omitempty"`
+}
+
+// SeasonHistoryRequest selects which seasonal board's history to return.
+type SeasonHistoryRequest struct {
+	Leaderboard string `json:"leaderboard"`
+	Mode        string `json:"mode"`
+	Seasons     int    `json:"seasons"`
+	Limit       int    `json:"limit"`
+}
+
+// SeasonEntry is one player's final placing in a past season.
+type SeasonEntry struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Rank     int64  `json:"rank"`
+	Score    int64  `json:"score"`
+	Reward   string `json:"reward,omitempty"`
+}
+
+// SeasonResult holds the final standings of one past season.
+type SeasonResult struct {
+	SeasonID    string        `json:"season_id"`
+	SeasonStart int64         `json:"season_start"`
+	SeasonEnd   int64         `json:"season_end"`
+	Standings   []SeasonEntry `json:"standings"`
+	Me          *SeasonEntry  `json:"me,omitempty"`
+}
+
+// SeasonHistoryResponse lists past seasons of a board, newest first.
+type SeasonHistoryResponse struct {
+	LeaderboardID string         `json:"leaderboard_id"`
+	Seasons       []SeasonResult `json:"seasons"`
+}
+
+// PlayerStatsRequest selects whose stats to return (default: the caller).
+type PlayerStatsRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// ModeStatsEntry is a player's totals in one game mode.
+type ModeStatsEntry struct {
+	Mode   string `json:"mode"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+}
+
+// PlayerStatsResponse holds a player's lifetime stats. For a bot,
+// SkillRating is its bot ladder rating and there is no per-mode breakdown.
+type PlayerStatsResponse struct {
+	UserID        string           `json:"user_id"`
+	Wins          int              `json:"wins"`
+	Losses        int              `json:"losses"`
+	Draws         int              `json:"draws"`
+	CurrentStreak int              `json:"current_streak"`
+	BestStreak    int              `json:"best_streak"`
+	SkillRating   int              `json:"skill_rating"`
+	Abandons      int              `json:"abandons"`
+	Modes         []ModeStatsEntry `json:"modes"`
+	IsBot         bool             `json:"is_bot,omitempty"`
+}
+
+// AchievementsRequest selects whose achievements to list (default: the caller).
+type AchievementsRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// AchievementEntry is one achievement and whether the player has it.
+type AchievementEntry struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Unlocked    bool   `json:"unlocked"`
+	UnlockedAt  int64  `json:"unlocked_at,omitempty"`
+}
+
+// AchievementsResponse lists every achievement with the player's progress.
+type AchievementsResponse struct {
+	UserID       string             `json:"user_id"`
+	Unlocked     int                `json:"unlocked"`
+	Total        int                `json:"total"`
+	Achievements []AchievementEntry `json:"achievements"`
+}
+
+// ProgressionRequest selects whose progression to show (default: the caller).
+type ProgressionRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// QuestEntry is one quest in the current rotation with the player's progress.
+type QuestEntry struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Period    string `json:"period"`
+	Mode      string `json:"mode,omitempty"`
+	Progress  int    `json:"progress"`
+	Target    int    `json:"target"`
+	RewardXP  int64  `json:"reward_xp"`
+	Completed bool   `json:"completed"`
+	Claimed   bool   `json:"claimed"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// ProgressionResponse is a player's XP, level and active quests.
+type ProgressionResponse struct {
+	UserID      string       `json:"user_id"`
+	XP          int64        `json:"xp"`
+	Level       int          `json:"level"`
+	NextLevelXP int64        `json:"next_level_xp"`
+	Quests      []QuestEntry `json:"quests"`
+}
+
+// ClaimQuestRequest is the payload for claim_quest_reward.
+type ClaimQuestRequest struct {
+	QuestID string `json:"quest_id"`
+}
+
+// ClaimQuestResponse reports the XP credited for a claimed quest.
+type ClaimQuestResponse struct {
+	QuestID     string `json:"quest_id"`
+	RewardXP    int64  `json:"reward_xp"`
+	XP          int64  `json:"xp"`
+	Level       int    `json:"level"`
+	NextLevelXP int64  `json:"next_level_xp"`
+}
+
+// WalletRequest pages through the caller's wallet ledger.
+type WalletRequest struct {
+	Limit  int    `json:"limit"`
+	Cursor string `json:"cursor"`
+}
+
+// LedgerEntry is one wallet movement.
+type LedgerEntry struct {
+	ID         string                 `json:"id"`
+	Change     int64                  `json:"change"`
+	Reason     string                 `json:"reason"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	CreateTime int64                  `json:"create_time"`
+}
+
+// WalletResponse is the caller's coin balance and a page of their ledger.
+type WalletResponse struct {
+	Coins      int64         `json:"coins"`
+	Ledger     []LedgerEntry `json:"ledger"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// DailyRewardResponse reports the outcome of claim_daily_reward.
+type DailyRewardResponse struct {
+	Claimed bool  `json:"claimed"`
+	Reward  int64 `json:"reward"`
+	Coins   int64 `json:"coins"`
+}
+
+// CatalogueItem is a store item with the caller's ownership of it.
+type CatalogueItem struct {
+	match.CosmeticItem
+	Owned    bool `json:"owned"`
+	Equipped bool `json:"equipped"`
+}
+
+// CatalogueResponse is the store catalogue and the caller's balance.
+type CatalogueResponse struct {
+	Coins int64           `json:"coins"`
+	Items []CatalogueItem `json:"items"`
+}
+
+// ItemRequest names a store item to purchase or equip.
+type ItemRequest struct {
+	ItemID string `json:"item_id"`
+}
+
+// InventoryResponse is the caller's inventory after a purchase or equip.
+type InventoryResponse struct {
+	Owned    []string          `json:"owned"`
+	Equipped map[string]string `json:"equipped"`
+	Coins    int64             `json:"coins"`
+}
+
+// ClanCreateRequest is the payload for create_clan.
+type ClanCreateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Open        bool   `json:"open"`
+}
+
+// ClanRequest identifies a clan (a Nakama group ID).
+type ClanRequest struct {
+	ClanID string `json:"clan_id"`
+}
+
+// ClanResponse describes a clan and its standing on the clan leaderboard.
+type ClanResponse struct {
+	ClanID      string `json:"clan_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Open        bool   `json:"open"`
+	Members     int    `json:"members"`
+	Wins        int64  `json:"wins"`
+	Rank        int64  `json:"rank,omitempty"`
+}
+
+// ClanWarCreateRequest schedules a war between two clans. StartsAt is a
+// Unix timestamp; zero starts the war immediately.
+type ClanWarCreateRequest struct {
+	ClanA           string `json:"clan_a"`
+	ClanB           string `json:"clan_b"`
+	Mode            string `json:"mode"`
+	StartsAt        int64  `json:"starts_at"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+// ClanWarRequest identifies a clan war.
+type ClanWarRequest struct {
+	WarID string `json:"war_id"`
+}
+
+// ClanWarStanding is the caller's own participation in a clan war.
+type ClanWarStanding struct {
+	ClanID      string `json:"clan_id"`
+	Status      string `json:"status"`
+	MatchID     string `json:"match_id,omitempty"`
+	Points      int    `json:"points"`
+	GamesPlayed int    `json:"games_played"`
+}
+
+// ClanWarResponse describes a clan war and its running score.
+type ClanWarResponse struct {
+	WarID    string           `json:"war_id"`
+	ClanA    string           `json:"clan_a"`
+	ClanB    string           `json:"clan_b"`
+	Mode     string           `json:"mode"`
+	StartsAt int64            `json:"starts_at"`
+	EndsAt   int64            `json:"ends_at"`
+	Running  bool             `json:"running"`
+	ScoreA   int              `json:"score_a"`
+	ScoreB   int              `json:"score_b"`
+	Me       *ClanWarStanding `json:"me,omitempty"`
+}
+
+// ClanWarListResponse lists a clan's upcoming and running wars.
+type ClanWarListResponse struct {
+	ClanID string            `json:"clan_id"`
+	Wars   []ClanWarResponse `json:"wars"`
+}
+
+// MuteRequest mutes or unmutes the caller's opponent in a match.
+type MuteRequest struct {
+	MatchID string `json:"match_id"`
+	Muted   bool   `json:"muted"`
+}
+
+// TranscriptRequest selects a match's chat transcript.
+type TranscriptRequest struct {
+	MatchID string `json:"match_id"`
+	Limit   int    `json:"limit"`
+}
+
+// TranscriptEntry is one chat line.
+type TranscriptEntry struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Message   string `json:"message"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TranscriptResponse is a match's chat in the order it was sent.
+type TranscriptResponse struct {
+	MatchID  string            `json:"match_id"`
+	Messages []TranscriptEntry `json:"messages"`
+}
+
+// ReportRequest reports a player from a match. ReportedUserID defaults to
+// the caller's opponent. Game selects the game whose moves are attached,
+// as in GameReviewRequest; omitted, it is the game in progress or, once
+// the match has ended, its latest game.
+type ReportRequest struct {
+	MatchID        string `json:"match_id"`
+	ReportedUserID string `json:"reported_user_id"`
+	Category       string `json:"category"`
+	Details        string `json:"details"`
+	Game           *int   `json:"game"`
+}
+
+// ListReportsRequest selects reports from the moderation queue.
+type ListReportsRequest struct {
+	Status string `json:"status"`
+	Limit  int    `json:"limit"`
+}
+
+// ClaimReportRequest assigns a report to a moderator.
+type ClaimReportRequest struct {
+	ReportID  string `json:"report_id"`
+	Moderator string `json:"moderator"`
+}
+
+// ResolveReportRequest closes a claimed report.
+type ResolveReportRequest struct {
+	ReportID   string `json:"report_id"`
+	Moderator  string `json:"moderator"`
+	Action     string `json:"action"`
+	Resolution string `json:"resolution"`
+}
+
+// ReportResponse is one report with its attached evidence.
+type ReportResponse struct {
+	ReportID        string          `json:"report_id"`
+	ReporterID      string          `json:"reporter_id"`
+	ReportedID      string          `json:"reported_id"`
+	ReportedFlagged bool            `json:"reported_flagged"`
+	MatchID         string          `json:"match_id"`
+	Category        string          `json:"category"`
+	Details         string          `json:"details"`
+	Transcript      json.RawMessage `json:"transcript"`
+	Moves           json.RawMessage `json:"moves"`
+	Status          string          `json:"status"`
+	ClaimedBy       string          `json:"claimed_by,omitempty"`
+	Action          string          `json:"action,omitempty"`
+	Resolution      string          `json:"resolution,omitempty"`
+	CreatedAt       int64           `json:"created_at"`
+}
+
+// ReportListResponse is a page of the moderation queue.
+type ReportListResponse struct {
+	Reports []ReportResponse `json:"reports"`
+}
+
+// BlockRequest blocks or unblocks a player. MatchID optionally names a
+// live match the caller shares with them.
+type BlockRequest struct {
+	TargetUserID string `json:"target_user_id"`
+	MatchID      string `json:"match_id"`
+}
+
+// BlockedPlayerResponse is one entry of a block list.
+type BlockedPlayerResponse struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	BlockedAt int64  `json:"blocked_at"`
+}
+
+// BlockListResponse is the caller's block list.
+type BlockListResponse struct {
+	Players []BlockedPlayerResponse `json:"players"`
+}
+
+// AnalyzeRequest is a position to evaluate. Board is row-major with "X",
+// "O" or "" per cell, like the match board. WinLength defaults to the
+// board width and Depth to the solver default. Misere analyses under
+// misère rules.
+type AnalyzeRequest struct {
+	Board     []string `json:"board"`
+	ToMove    string   `json:"to_move"`
+	WinLength int      `json:"win_length"`
+	Depth     int      `json:"depth"`
+	Misere    bool     `json:"misere"`
+}
+
+// GameReviewRequest selects a finished game to review. Game is the game's
+// index within the match (0 for the first, 1 for the first rematch, ...);
+// omitted, the match's latest game is reviewed.
+type GameReviewRequest struct {
+	MatchID string `json:"match_id"`
+	Game    *int   `json:"game"`
+}
+
+// PuzzleResponse is today's puzzle with the caller's progress. Current is
+// the board after Moves; in ultimate puzzles CurrentNextBoard is the small
+// board to play in next (-1 for any).
+type PuzzleResponse struct {
+	*match.Puzzle
+	Current          []string `json:"current"`
+	CurrentNextBoard *int     `json:"current_next_board,omitempty"`
+	Moves            []int    `json:"moves"`
+	Attempts         int      `json:"attempts"`
+	Solved           bool     `json:"solved"`
+	SolveMs          int64    `json:"solve_ms,omitempty"`
+}
+
+// PuzzleMoveRequest is the caller's next move in today's puzzle.
+type PuzzleMoveRequest struct {
+	Date     string `json:"date"`
+	Position int    `json:"position"`
+}
+
+// PuzzleMoveResponse is the verdict on a puzzle move. Moves is the current
+// try after it (empty after a wrong move).
+type PuzzleMoveResponse struct {
+	match.PuzzleMoveResult
+	Moves    []int `json:"moves"`
+	Attempts int   `json:"attempts"`
+	SolveMs  int64 `json:"solve_ms,omitempty"`
+}
+
+// LearningDayResponse is one day of the learning bot's results.
+type LearningDayResponse struct {
+	match.LearningDay
+	WinRate float64 `json:"win_rate"`
+}
+
+// LearningStatsResponse is the learning bot's record overall and per day,
+// oldest day first, so clients can chart its win rate over time.
+type LearningStatsResponse struct {
+	Games   int                   `json:"games"`
+	Wins    int                   `json:"wins"`
+	Draws   int                   `json:"draws"`
+	Losses  int                   `json:"losses"`
+	WinRate float64               `json:"win_rate"`
+	Days    []LearningDayResponse `json:"days"`
+}
+
+// RegisterBotRequest registers an existing account as an external bot.
+// Name defaults to the account's username.
+type RegisterBotRequest struct {
+	TargetUserID string `json:"target_user_id"`
+	Name         string `json:"name"`
+	Owner        string `json:"owner"`
+}
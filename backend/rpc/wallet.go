@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	dbpkg "github.com/prasanth-33460/tic-tac-toe/backend/db"
+	"github.com/prasanth-33460/tic-tac-toe/backend/match"
+)
+
+const (
+	defaultLedgerLimit = 20
+	maxLedgerLimit     = 100
+)
+
+// RPCGetWallet returns the caller's coin balance and a page of their
+// wallet ledger, newest first.
+func RPCGetWallet(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	var req WalletRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			return "", fmt.Errorf("invalid request")
+		}
+	}
+	if req.Limit <= 0 || req.Limit > maxLedgerLimit {
+		req.Limit = defaultLedgerLimit
+	}
+
+	coins, err := match.WalletBalance(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Wallet fetch failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	items, next, err := nk.WalletLedgerList(ctx, userID, req.Limit, req.Cursor)
+	if err != nil {
+		logger.Error("Ledger fetch failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := WalletResponse{
+		Coins:      coins,
+		Ledger:     make([]LedgerEntry, 0, len(items)),
+		NextCursor: next,
+	}
+	for _, item := range items {
+		metadata := item.GetMetadata()
+		reason, _ := metadata["reason"].(string)
+		response.Ledger = append(response.Ledger, LedgerEntry{
+			ID:         item.GetID(),
+			Change:     item.GetChangeset()[match.WalletCurrency],
+			Reason:     reason,
+			Metadata:   metadata,
+			CreateTime: item.GetCreateTime(),
+		})
+	}
+
+	return marshalResponse(response, logger)
+}
+
+// RPCClaimDailyReward credits the daily login coins once per UTC day.
+// Claiming again the same day is not an error; it reports claimed=false.
+func RPCClaimDailyReward(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", fmt.Errorf("authentication required")
+	}
+
+	repo := dbpkg.NewRepository(db)
+	today := time.Now().UTC()
+	claimed, err := repo.ClaimDailyLogin(ctx, userID, today, match.DailyLoginCoins)
+	if err != nil {
+		logger.Error("Daily reward claim failed for %s: %v", userID, err)
+		return "", fmt.Errorf("internal error")
+	}
+
+	response := DailyRewardResponse{Claimed: claimed}
+	if claimed {
+		metadata := map[string]interface{}{"reason": match.LedgerDailyLogin, "day": today.Format("2006-01-02")}
+		if err := match.CreditCoins(ctx, nk, userID, match.DailyLoginCoins, metadata); err != nil {
+			logger.Error("Daily reward credit failed for %s: %v", userID, err)
+			if err := repo.ReleaseDailyLogin(ctx, userID, today); err != nil {
+				logger.Error("Daily reward release failed for %s: %v", userID, err)
+			}
+			return "", fmt.Errorf("internal error")
+		}
+		response.Reward = match.DailyLoginCoins
+	}
+
+	if response.Coins, err = match.WalletBalance(ctx, nk, userID); err != nil {
+		logger.Warn("Wallet fetch failed for %s: %v", userID, err)
+	}
+
+	return marshalResponse(response, logger)
+}
@@ -0,0 +1,173 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Board symbols. They match the match package's SymbolX and SymbolO, and
+// empty cells are "" just like MatchState.Board.
+const (
+	SymbolX = "X"
+	SymbolO = "O"
+)
+
+// Board size limits. Larger boards are searched to a limited depth only.
+const (
+	MinSize = 3
+	MaxSize = 7
+)
+
+// Board is a square board in row-major order, the same layout CheckWinner
+// reads. WinLength is how many marks in a row end the game; under Misere
+// rules whoever completes the line loses.
+type Board struct {
+	Cells     []string
+	Size      int
+	WinLength int
+	Misere    bool
+}
+
+// NewBoard validates cells and returns the board. winLength 0 means a full
+// row, column or diagonal.
+func NewBoard(cells []string, winLength int) (*Board, error) {
+	size := int(math.Sqrt(float64(len(cells))))
+	if size*size != len(cells) || size < MinSize || size > MaxSize {
+		return nil, fmt.Errorf("board must be square with %d-%d cells per side", MinSize, MaxSize)
+	}
+	if winLength == 0 {
+		winLength = size
+	}
+	if winLength < MinSize || winLength > size {
+		return nil, fmt.Errorf("win length must be between %d and %d", MinSize, size)
+	}
+	for i, c := range cells {
+		if c != "" && c != SymbolX && c != SymbolO {
+			return nil, fmt.Errorf("invalid symbol %q at %d", c, i)
+		}
+	}
+	return &Board{Cells: cells, Size: size, WinLength: winLength}, nil
+}
+
+// Opponent returns the other symbol.
+func Opponent(symbol string) string {
+	if symbol == SymbolX {
+		return SymbolO
+	}
+	return SymbolX
+}
+
+// Winner returns the winner's symbol, or "" if nobody has a line yet. Under
+// Misere rules that is the opponent of the symbol with the line.
+func (b *Board) Winner() string {
+	for _, line := range Lines(b.Size, b.WinLength) {
+		first := b.Cells[line[0]]
+		if first == "" {
+			continue
+		}
+		won := true
+		for _, idx := range line[1:] {
+			if b.Cells[idx] != first {
+				won = false
+				break
+			}
+		}
+		if won {
+			if b.Misere {
+				return Opponent(first)
+			}
+			return first
+		}
+	}
+	return ""
+}
+
+// Full reports whether every cell is taken.
+func (b *Board) Full() bool {
+	for _, c := range b.Cells {
+		if c == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// geometry caches the winning lines and symmetries of one board shape.
+type geometry struct {
+	lines [][]int
+	syms  [][]int
+}
+
+var (
+	geometryMu sync.Mutex
+	geometries = map[[2]int]*geometry{}
+)
+
+func geometryFor(size, winLength int) *geometry {
+	geometryMu.Lock()
+	defer geometryMu.Unlock()
+
+	key := [2]int{size, winLength}
+	if g, ok := geometries[key]; ok {
+		return g
+	}
+	g := &geometry{lines: buildLines(size, winLength), syms: buildSymmetries(size)}
+	geometries[key] = g
+	return g
+}
+
+// Lines returns every run of winLength cells on a size×size board. For
+// the classic board this is the same set as match.WinPatterns.
+func Lines(size, winLength int) [][]int {
+	return geometryFor(size, winLength).lines
+}
+
+func buildLines(size, k int) [][]int {
+	var lines [][]int
+	directions := [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			for _, d := range directions {
+				endRow, endCol := row+d[0]*(k-1), col+d[1]*(k-1)
+				if endRow < 0 || endRow >= size || endCol < 0 || endCol >= size {
+					continue
+				}
+				line := make([]int, k)
+				for i := 0; i < k; i++ {
+					line[i] = (row+d[0]*i)*size + col + d[1]*i
+				}
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// buildSymmetries returns the eight rotations and reflections of the
+// board as index maps: cell i of the transformed board is cell sym[i].
+func buildSymmetries(size int) [][]int {
+	transforms := []func(r, c int) (int, int){
+		func(r, c int) (int, int) { return r, c },
+		func(r, c int) (int, int) { return c, size - 1 - r },
+		func(r, c int) (int, int) { return size - 1 - r, size - 1 - c },
+		func(r, c int) (int, int) { return size - 1 - c, r },
+		func(r, c int) (int, int) { return r, size - 1 - c },
+		func(r, c int) (int, int) { return size - 1 - r, c },
+		func(r, c int) (int, int) { return c, r },
+		func(r, c int) (int, int) { return size - 1 - c, size - 1 - r },
+	}
+
+	syms := make([][]int, len(transforms))
+	for t, transform := range transforms {
+		sym := make([]int, size*size)
+		for r := 0; r < size; r++ {
+			for c := 0; c < size; c++ {
+				sr, sc := transform(r, c)
+				sym[r*size+c] = sr*size + sc
+			}
+		}
+		syms[t] = sym
+	}
+	return syms
+}
@@ -0,0 +1,403 @@
+// Package solver computes the game-theoretic value of tic-tac-toe
+// positions, under normal or misère rules. The classic 3×3 board is solved
+// exactly from a memoized table of every reachable position; larger boards
+// are searched to a limited depth.
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Outcome is the result of a position for the side to move, assuming both
+// sides play perfectly from here.
+type Outcome string
+
+const (
+	Win  Outcome = "win"
+	Draw Outcome = "draw"
+	Loss Outcome = "loss"
+	// Unknown means the depth limit was reached before the result was
+	// proven. Only possible on larger boards.
+	Unknown Outcome = "unknown"
+)
+
+// DefaultDepth is the search depth in plies on boards larger than 3×3.
+const DefaultDepth = 6
+
+// Scores are from the side to move's point of view. A win in n plies
+// scores winScore-n; heuristic scores stay well inside ±decisive.
+const (
+	winScore = 1000
+	decisive = winScore - MaxSize*MaxSize
+)
+
+// Evaluation is the value of a position or of one move. Distance is the
+// number of plies, both sides counted, until the game is won or lost.
+type Evaluation struct {
+	Outcome  Outcome `json:"outcome"`
+	Distance int     `json:"distance,omitempty"`
+	Score    int     `json:"score"`
+}
+
+// MoveEvaluation is the value of playing at Position, from the point of
+// view of the player making the move.
+type MoveEvaluation struct {
+	Position int `json:"position"`
+	Evaluation
+}
+
+// Analysis is a position's value with every legal move ranked. Exact is
+// false when a larger board was cut off by the depth limit.
+type Analysis struct {
+	Evaluation
+	Exact     bool             `json:"exact"`
+	BestMoves []int            `json:"best_moves"`
+	Moves     []MoveEvaluation `json:"moves"`
+}
+
+// Analyze evaluates the board for toMove. maxDepth only applies to boards
+// larger than 3×3; 0 means DefaultDepth.
+func Analyze(b *Board, toMove string, maxDepth int) (*Analysis, error) {
+	if toMove != SymbolX && toMove != SymbolO {
+		return nil, fmt.Errorf("side to move must be %s or %s", SymbolX, SymbolO)
+	}
+
+	pos, me := encode(b.Cells, toMove)
+	g := geometryFor(b.Size, b.WinLength)
+
+	if score, over := terminalScore(pos, me, g.lines, b.Misere); over {
+		return &Analysis{Evaluation: evaluation(score, true), Exact: true, BestMoves: []int{}, Moves: []MoveEvaluation{}}, nil
+	}
+
+	var value func(child []byte) int
+	exact := true
+	if b.Size == 3 && b.WinLength == 3 {
+		s := &exactSolver{g: g, misere: b.Misere, base: classicTable(b.Misere), memo: map[string]int{}}
+		value = func(child []byte) int { return s.value(child, other(me)) }
+	} else {
+		if maxDepth <= 0 {
+			maxDepth = DefaultDepth
+		}
+		depth := min(maxDepth, empties(pos))
+		exact = depth >= empties(pos)
+		s := &searcher{g: g, misere: b.Misere, tt: map[string]ttEntry{}}
+		value = func(child []byte) int {
+			return s.negamax(child, other(me), depth-1, -math.MaxInt32, math.MaxInt32)
+		}
+	}
+
+	analysis := &Analysis{Exact: exact}
+	best := math.MinInt32
+	seen := map[string]int{}
+	for i, cell := range pos {
+		if cell != 0 {
+			continue
+		}
+		pos[i] = me
+		// Moves that are mirror images of an earlier move share its value.
+		key := canonicalKey(pos, other(me), g.syms)
+		score, ok := seen[key]
+		if !ok {
+			score = parentScore(value(pos))
+			seen[key] = score
+		}
+		pos[i] = 0
+
+		analysis.Moves = append(analysis.Moves, MoveEvaluation{Position: i, Evaluation: evaluation(score, exact)})
+		best = max(best, score)
+	}
+
+	sort.SliceStable(analysis.Moves, func(i, j int) bool {
+		return analysis.Moves[i].Score > analysis.Moves[j].Score
+	})
+	for _, m := range analysis.Moves {
+		if m.Score == best {
+			analysis.BestMoves = append(analysis.BestMoves, m.Position)
+		}
+	}
+	analysis.Evaluation = evaluation(best, exact)
+	return analysis, nil
+}
+
+func evaluation(score int, exact bool) Evaluation {
+	switch {
+	case score > decisive:
+		return Evaluation{Outcome: Win, Distance: winScore - score, Score: score}
+	case score < -decisive:
+		return Evaluation{Outcome: Loss, Distance: winScore + score, Score: score}
+	case exact:
+		return Evaluation{Outcome: Draw, Score: score}
+	default:
+		return Evaluation{Outcome: Unknown, Score: score}
+	}
+}
+
+// parentScore turns a child's score into the score of the move leading to
+// it: the sign flips and a decisive result is one ply further away.
+func parentScore(child int) int {
+	score := -child
+	switch {
+	case score > decisive:
+		score--
+	case score < -decisive:
+		score++
+	}
+	return score
+}
+
+// Positions are encoded as one byte per cell: 0 empty, 1 X, 2 O.
+
+func encode(cells []string, toMove string) ([]byte, byte) {
+	pos := make([]byte, len(cells))
+	for i, c := range cells {
+		switch c {
+		case SymbolX:
+			pos[i] = 1
+		case SymbolO:
+			pos[i] = 2
+		}
+	}
+	if toMove == SymbolX {
+		return pos, 1
+	}
+	return pos, 2
+}
+
+func other(me byte) byte {
+	return 3 - me
+}
+
+func empties(pos []byte) int {
+	n := 0
+	for _, c := range pos {
+		if c == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// terminalScore scores a finished position for me. Under misère rules a
+// line loses for whoever made it.
+func terminalScore(pos []byte, me byte, lines [][]int, misere bool) (int, bool) {
+	for _, line := range lines {
+		first := pos[line[0]]
+		if first == 0 {
+			continue
+		}
+		won := true
+		for _, idx := range line[1:] {
+			if pos[idx] != first {
+				won = false
+				break
+			}
+		}
+		if won {
+			if (first == me) != misere {
+				return winScore, true
+			}
+			return -winScore, true
+		}
+	}
+	if empties(pos) == 0 {
+		return 0, true
+	}
+	return 0, false
+}
+
+// canonicalKey identifies a position up to symmetry and colour: the side
+// to move is always written as 1, and the smallest of the eight
+// rotations/reflections is used.
+func canonicalKey(pos []byte, me byte, syms [][]int) string {
+	buf := make([]byte, len(pos))
+	var best []byte
+	for _, sym := range syms {
+		for i, src := range sym {
+			c := pos[src]
+			if c != 0 && me == 2 {
+				c = 3 - c
+			}
+			buf[i] = c
+		}
+		if best == nil || string(buf) < string(best) {
+			best = append(best[:0], buf...)
+		}
+	}
+	return string(best)
+}
+
+// exactSolver solves 3×3 positions by full negamax. Positions in base (the
+// shared classic table for the same rules) are never recomputed.
+type exactSolver struct {
+	g      *geometry
+	misere bool
+	base   map[string]int
+	memo   map[string]int
+}
+
+func (s *exactSolver) value(pos []byte, me byte) int {
+	key := canonicalKey(pos, me, s.g.syms)
+	if v, ok := s.base[key]; ok {
+		return v
+	}
+	if v, ok := s.memo[key]; ok {
+		return v
+	}
+
+	v, over := terminalScore(pos, me, s.g.lines, s.misere)
+	if !over {
+		v = math.MinInt32
+		for i, cell := range pos {
+			if cell != 0 {
+				continue
+			}
+			pos[i] = me
+			v = max(v, parentScore(s.value(pos, other(me))))
+			pos[i] = 0
+		}
+	}
+	s.memo[key] = v
+	return v
+}
+
+// Classic tables, indexed by misère: [0] normal rules, [1] misère.
+var (
+	classicOnce  [2]sync.Once
+	classicCache [2]map[string]int
+)
+
+// classicTable returns the value of every position reachable from the
+// empty 3×3 board under the given rules, built on first use and read-only
+// afterwards.
+func classicTable(misere bool) map[string]int {
+	i := 0
+	if misere {
+		i = 1
+	}
+	classicOnce[i].Do(func() {
+		s := &exactSolver{g: geometryFor(3, 3), misere: misere, memo: map[string]int{}}
+		s.value(make([]byte, 9), 1)
+		classicCache[i] = s.memo
+	})
+	return classicCache[i]
+}
+
+// Transposition table bounds.
+const (
+	boundExact = iota
+	boundLower
+	boundUpper
+)
+
+type ttEntry struct {
+	depth int
+	score int
+	bound int
+}
+
+// searcher runs depth-limited alpha-beta on larger boards.
+type searcher struct {
+	g      *geometry
+	misere bool
+	tt     map[string]ttEntry
+}
+
+func (s *searcher) negamax(pos []byte, me byte, depth, alpha, beta int) int {
+	if score, over := terminalScore(pos, me, s.g.lines, s.misere); over {
+		return score
+	}
+	if depth <= 0 {
+		return s.heuristic(pos, me)
+	}
+
+	key := canonicalKey(pos, me, s.g.syms)
+	if e, ok := s.tt[key]; ok && e.depth >= depth {
+		switch {
+		case e.bound == boundExact:
+			return e.score
+		case e.bound == boundLower && e.score >= beta:
+			return e.score
+		case e.bound == boundUpper && e.score <= alpha:
+			return e.score
+		}
+	}
+
+	origAlpha := alpha
+	best := math.MinInt32
+	for _, i := range s.orderedMoves(pos) {
+		pos[i] = me
+		// Widen the window by one ply so decisive scores survive the
+		// parentScore adjustment.
+		score := parentScore(s.negamax(pos, other(me), depth-1, -beta-1, -alpha+1))
+		pos[i] = 0
+
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	bound := boundExact
+	switch {
+	case best <= origAlpha:
+		bound = boundUpper
+	case best >= beta:
+		bound = boundLower
+	}
+	s.tt[key] = ttEntry{depth: depth, score: best, bound: bound}
+	return best
+}
+
+// orderedMoves lists empty cells, central cells first, so alpha-beta
+// cuts early.
+func (s *searcher) orderedMoves(pos []byte) []int {
+	size := s.g.size()
+	center := float64(size-1) / 2
+	var moves []int
+	for i, c := range pos {
+		if c == 0 {
+			moves = append(moves, i)
+		}
+	}
+	dist := func(i int) float64 {
+		return math.Abs(float64(i/size)-center) + math.Abs(float64(i%size)-center)
+	}
+	sort.SliceStable(moves, func(a, b int) bool { return dist(moves[a]) < dist(moves[b]) })
+	return moves
+}
+
+// heuristic scores an unfinished position by its open lines: each line
+// only one side has marks in is worth the square of their mark count.
+// Under misère rules such lines are a liability, so the sign flips.
+func (s *searcher) heuristic(pos []byte, me byte) int {
+	score := 0
+	for _, line := range s.g.lines {
+		mine, theirs := 0, 0
+		for _, idx := range line {
+			switch pos[idx] {
+			case me:
+				mine++
+			case other(me):
+				theirs++
+			}
+		}
+		switch {
+		case theirs == 0:
+			score += mine * mine
+		case mine == 0:
+			score -= theirs * theirs
+		}
+	}
+	if s.misere {
+		score = -score
+	}
+	return max(min(score, decisive/2), -decisive/2)
+}
+
+func (g *geometry) size() int {
+	return int(math.Sqrt(float64(len(g.syms[0]))))
+}
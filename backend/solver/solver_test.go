@@ -0,0 +1,154 @@
+package solver
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// cells parses a board written row by row, e.g. "XO./.X./..O", with "."
+// for an empty cell and "/" between rows.
+func cells(rows string) []string {
+	var out []string
+	for _, c := range strings.ReplaceAll(rows, "/", "") {
+		switch c {
+		case 'X':
+			out = append(out, SymbolX)
+		case 'O':
+			out = append(out, SymbolO)
+		default:
+			out = append(out, "")
+		}
+	}
+	return out
+}
+
+func TestAnalyzeClassic(t *testing.T) {
+	tests := []struct {
+		name      string
+		board     string
+		toMove    string
+		outcome   Outcome
+		distance  int
+		bestMoves []int
+	}{
+		{"empty board is a draw", ".../.../...", SymbolX, Draw, 0, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+		{"win in one", "XX./OO./...", SymbolX, Win, 1, []int{2}},
+		{"block the only threat", "XX./.O./...", SymbolO, Draw, 0, []int{2}},
+		{"edge avoids the corner trap", "X../.O./..X", SymbolO, Draw, 0, []int{1, 3, 5, 7}},
+		{"lost to a fork", "X.X/.O./O.X", SymbolO, Loss, 2, []int{1, 3, 5, 7}},
+		{"already lost", "XXX/OO./...", SymbolO, Loss, 0, nil},
+		{"full board draw", "XOX/XOO/OXX", SymbolX, Draw, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := NewBoard(cells(tt.board), 0)
+			if err != nil {
+				t.Fatalf("NewBoard: %v", err)
+			}
+			analysis, err := Analyze(board, tt.toMove, 0)
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			if !analysis.Exact {
+				t.Errorf("3×3 analysis not exact")
+			}
+			if analysis.Outcome != tt.outcome || analysis.Distance != tt.distance {
+				t.Errorf("got %s in %d, want %s in %d", analysis.Outcome, analysis.Distance, tt.outcome, tt.distance)
+			}
+			best := slices.Clone(analysis.BestMoves)
+			slices.Sort(best)
+			if !slices.Equal(best, tt.bestMoves) {
+				t.Errorf("best moves %v, want %v", best, tt.bestMoves)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRejectsBadSideToMove(t *testing.T) {
+	board, err := NewBoard(cells(".../.../..."), 0)
+	if err != nil {
+		t.Fatalf("NewBoard: %v", err)
+	}
+	if _, err := Analyze(board, "Z", 0); err == nil {
+		t.Errorf("Analyze accepted side to move Z")
+	}
+}
+
+func TestNewBoardValidates(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []string
+		winLength int
+	}{
+		{"not square", make([]string, 8), 0},
+		{"win length too long", make([]string, 9), 4},
+		{"win length too short", make([]string, 16), 2},
+		{"bad symbol", []string{"X", "Q", "", "", "", "", "", "", ""}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBoard(tt.cells, tt.winLength); err == nil {
+				t.Errorf("NewBoard accepted an invalid board")
+			}
+		})
+	}
+}
+
+func TestAnalyzeMisere(t *testing.T) {
+	tests := []struct {
+		name      string
+		board     string
+		toMove    string
+		misere    bool
+		outcome   Outcome
+		distance  int
+		bestMoves []int
+	}{
+		{"forced line wins normally", "XX./OOX/XOO", SymbolX, false, Win, 1, []int{2}},
+		{"forced line loses in misère", "XX./OOX/XOO", SymbolX, true, Loss, 1, []int{2}},
+		{"completed line loses in misère", "XXX/OO./...", SymbolO, true, Win, 0, nil},
+		{"empty misère board", ".../.../...", SymbolX, true, Draw, 0, []int{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := NewBoard(cells(tt.board), 0)
+			if err != nil {
+				t.Fatalf("NewBoard: %v", err)
+			}
+			board.Misere = tt.misere
+			analysis, err := Analyze(board, tt.toMove, 0)
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			if analysis.Outcome != tt.outcome || analysis.Distance != tt.distance {
+				t.Errorf("got %s in %d, want %s in %d", analysis.Outcome, analysis.Distance, tt.outcome, tt.distance)
+			}
+			best := slices.Clone(analysis.BestMoves)
+			slices.Sort(best)
+			if !slices.Equal(best, tt.bestMoves) {
+				t.Errorf("best moves %v, want %v", best, tt.bestMoves)
+			}
+		})
+	}
+}
+
+func TestAnalyzeMisereCompletingMoveLoses(t *testing.T) {
+	board, err := NewBoard(cells("X.O/.O./X.."), 0)
+	if err != nil {
+		t.Fatalf("NewBoard: %v", err)
+	}
+	board.Misere = true
+	analysis, err := Analyze(board, SymbolX, 0)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if slices.Contains(analysis.BestMoves, 3) {
+		t.Errorf("completing the column at 3 ranked best: %v", analysis.BestMoves)
+	}
+	for _, m := range analysis.Moves {
+		if m.Position == 3 && (m.Outcome != Loss || m.Distance != 1) {
+			t.Errorf("move 3 = %s in %d, want loss in 1", m.Outcome, m.Distance)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Ultimate tic-tac-toe is nine classic boards in a 3×3 grid. The cell a
+// player picks inside a small board sends the opponent to the small board
+// in the same place; if that board is already decided, the opponent may
+// play in any open board. Three small boards in a row win.
+const (
+	// UltimateSize is the width of the full ultimate board in cells.
+	UltimateSize = 9
+
+	// AnyBoard is the Next value when the side to move may play in any
+	// open small board.
+	AnyBoard = -1
+
+	// ultimateMaxDepth bounds the search. Deeper searches would let a
+	// decisive distance approach the heuristic range.
+	ultimateMaxDepth = 12
+
+	// Small board states in the macro board.
+	smallOpen  = 0
+	smallDrawn = 3
+)
+
+// UltimateBoard is an ultimate position. Cells is the full 9×9 board in
+// row-major order, like Board.Cells; Next is the small board (0-8,
+// row-major) the side to move must play in, or AnyBoard.
+type UltimateBoard struct {
+	Cells []string
+	Next  int
+}
+
+// NewUltimateBoard validates cells and next and returns the board.
+func NewUltimateBoard(cells []string, next int) (*UltimateBoard, error) {
+	if len(cells) != UltimateSize*UltimateSize {
+		return nil, fmt.Errorf("ultimate board must have %d cells", UltimateSize*UltimateSize)
+	}
+	for i, c := range cells {
+		if c != "" && c != SymbolX && c != SymbolO {
+			return nil, fmt.Errorf("invalid symbol %q at %d", c, i)
+		}
+	}
+	if next < AnyBoard || next >= UltimateSize {
+		return nil, fmt.Errorf("next board must be between %d and %d", AnyBoard, UltimateSize-1)
+	}
+	if next != AnyBoard && newUltimatePosition(cells).macro[next] != smallOpen {
+		return nil, fmt.Errorf("next board %d is already decided", next)
+	}
+	return &UltimateBoard{Cells: cells, Next: next}, nil
+}
+
+// SmallBoard returns the small board a cell belongs to.
+func SmallBoard(pos int) int {
+	row, col := pos/UltimateSize, pos%UltimateSize
+	return row/3*3 + col/3
+}
+
+// smallCell returns a cell's index inside its small board, which is also
+// the small board it sends the opponent to.
+func smallCell(pos int) int {
+	row, col := pos/UltimateSize, pos%UltimateSize
+	return row%3*3 + col%3
+}
+
+// smallLines are the winning lines of one small board, by cell index
+// inside it. The same lines decide the macro board.
+var smallLines = buildLines(3, 3)
+
+// smallCells lists the cells of each small board in row-major order.
+var smallCells = func() [UltimateSize][UltimateSize]int {
+	var cells [UltimateSize][UltimateSize]int
+	for pos := 0; pos < UltimateSize*UltimateSize; pos++ {
+		cells[SmallBoard(pos)][smallCell(pos)] = pos
+	}
+	return cells
+}()
+
+// Winner returns the symbol with three small boards in a row, or "".
+func (u *UltimateBoard) Winner() string {
+	switch newUltimatePosition(u.Cells).winner() {
+	case 1:
+		return SymbolX
+	case 2:
+		return SymbolO
+	}
+	return ""
+}
+
+// Legal reports whether the side to move may play at pos.
+func (u *UltimateBoard) Legal(pos int) bool {
+	if pos < 0 || pos >= len(u.Cells) || u.Cells[pos] != "" {
+		return false
+	}
+	p := newUltimatePosition(u.Cells)
+	board := SmallBoard(pos)
+	return p.macro[board] == smallOpen && (u.Next == AnyBoard || u.Next == board)
+}
+
+// Moves lists the cells the side to move may play.
+func (u *UltimateBoard) Moves() []int {
+	return newUltimatePosition(u.Cells).moves(u.Next)
+}
+
+// Play puts symbol at pos and sends the opponent to their next board.
+// Callers must check Legal first.
+func (u *UltimateBoard) Play(pos int, symbol string) {
+	u.Cells[pos] = symbol
+	u.Next = smallCell(pos)
+	if newUltimatePosition(u.Cells).macro[u.Next] != smallOpen {
+		u.Next = AnyBoard
+	}
+}
+
+// AnalyzeUltimate evaluates the board for toMove, searching at most
+// maxDepth plies; 0 means DefaultDepth. Ultimate positions are never
+// solved exactly, so a result short of a proven win or loss is Unknown.
+func AnalyzeUltimate(u *UltimateBoard, toMove string, maxDepth int) (*Analysis, error) {
+	if toMove != SymbolX && toMove != SymbolO {
+		return nil, fmt.Errorf("side to move must be %s or %s", SymbolX, SymbolO)
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultDepth
+	}
+	depth := min(maxDepth, ultimateMaxDepth)
+
+	p := newUltimatePosition(u.Cells)
+	_, me := encode(u.Cells, toMove)
+	if w := p.winner(); w != 0 || p.closed() {
+		score := 0
+		switch {
+		case w == me:
+			score = winScore
+		case w != 0:
+			score = -winScore
+		}
+		return &Analysis{Evaluation: evaluation(score, true), Exact: true, BestMoves: []int{}, Moves: []MoveEvaluation{}}, nil
+	}
+
+	s := &ultimateSearcher{tt: map[string]ttEntry{}}
+	analysis := &Analysis{}
+	best := math.MinInt32
+	for _, i := range p.moves(u.Next) {
+		next, undo := p.play(i, me)
+		score := parentScore(s.negamax(p, other(me), next, depth-1, -math.MaxInt32, math.MaxInt32))
+		p.undo(i, undo)
+
+		analysis.Moves = append(analysis.Moves, MoveEvaluation{Position: i, Evaluation: evaluation(score, false)})
+		best = max(best, score)
+	}
+
+	sort.SliceStable(analysis.Moves, func(i, j int) bool {
+		return analysis.Moves[i].Score > analysis.Moves[j].Score
+	})
+	for _, m := range analysis.Moves {
+		if m.Score == best {
+			analysis.BestMoves = append(analysis.BestMoves, m.Position)
+		}
+	}
+	analysis.Evaluation = evaluation(best, false)
+	return analysis, nil
+}
+
+// ultimatePosition is the byte encoding of an ultimate board plus the
+// state of each small board: open, won by 1 or 2, or drawn.
+type ultimatePosition struct {
+	cells []byte
+	macro [UltimateSize]byte
+}
+
+func newUltimatePosition(cells []string) *ultimatePosition {
+	pos, _ := encode(cells, SymbolX)
+	p := &ultimatePosition{cells: pos}
+	for board := range p.macro {
+		p.macro[board] = p.smallState(board)
+	}
+	return p
+}
+
+// smallState works out whether a small board is open, won or drawn.
+func (p *ultimatePosition) smallState(board int) byte {
+	cells := smallCells[board]
+	for _, line := range smallLines {
+		first := p.cells[cells[line[0]]]
+		if first != 0 && p.cells[cells[line[1]]] == first && p.cells[cells[line[2]]] == first {
+			return first
+		}
+	}
+	for _, pos := range cells {
+		if p.cells[pos] == 0 {
+			return smallOpen
+		}
+	}
+	return smallDrawn
+}
+
+// winner returns 1 or 2 for a player with three small boards in a row.
+func (p *ultimatePosition) winner() byte {
+	for _, line := range smallLines {
+		first := p.macro[line[0]]
+		if (first == 1 || first == 2) && p.macro[line[1]] == first && p.macro[line[2]] == first {
+			return first
+		}
+	}
+	return 0
+}
+
+// closed reports whether no small board is left open.
+func (p *ultimatePosition) closed() bool {
+	for _, state := range p.macro {
+		if state == smallOpen {
+			return false
+		}
+	}
+	return true
+}
+
+// moves lists the legal cells when the side to move is sent to next.
+func (p *ultimatePosition) moves(next int) []int {
+	var moves []int
+	for board := range p.macro {
+		if p.macro[board] != smallOpen || (next != AnyBoard && next != board) {
+			continue
+		}
+		for _, pos := range smallCells[board] {
+			if p.cells[pos] == 0 {
+				moves = append(moves, pos)
+			}
+		}
+	}
+	return moves
+}
+
+// play marks pos for me and returns the opponent's next board and the
+// small board's previous state for undo.
+func (p *ultimatePosition) play(pos int, me byte) (next int, undo byte) {
+	board := SmallBoard(pos)
+	undo = p.macro[board]
+	p.cells[pos] = me
+	p.macro[board] = p.smallState(board)
+
+	next = smallCell(pos)
+	if p.macro[next] != smallOpen {
+		next = AnyBoard
+	}
+	return next, undo
+}
+
+func (p *ultimatePosition) undo(pos int, state byte) {
+	p.cells[pos] = 0
+	p.macro[SmallBoard(pos)] = state
+}
+
+// ultimateSearcher runs depth-limited alpha-beta on ultimate positions.
+// The next board is part of the position, so no symmetry is applied.
+type ultimateSearcher struct {
+	tt map[string]ttEntry
+}
+
+func (s *ultimateSearcher) negamax(p *ultimatePosition, me byte, next, depth, alpha, beta int) int {
+	if w := p.winner(); w != 0 {
+		if w == me {
+			return winScore
+		}
+		return -winScore
+	}
+	if p.closed() {
+		return 0
+	}
+	if depth <= 0 {
+		return p.heuristic(me)
+	}
+
+	key := string(p.cells) + string(rune('a'+next+1))
+	if e, ok := s.tt[key]; ok && e.depth >= depth {
+		switch {
+		case e.bound == boundExact:
+			return e.score
+		case e.bound == boundLower && e.score >= beta:
+			return e.score
+		case e.bound == boundUpper && e.score <= alpha:
+			return e.score
+		}
+	}
+
+	origAlpha := alpha
+	best := math.MinInt32
+	for _, i := range p.moves(next) {
+		childNext, undo := p.play(i, me)
+		score := parentScore(s.negamax(p, other(me), childNext, depth-1, -beta-1, -alpha+1))
+		p.undo(i, undo)
+
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	bound := boundExact
+	switch {
+	case best <= origAlpha:
+		bound = boundUpper
+	case best >= beta:
+		bound = boundLower
+	}
+	s.tt[key] = ttEntry{depth: depth, score: best, bound: bound}
+	return best
+}
+
+// heuristic scores open lines of small boards heavily and open lines
+// inside the small boards still being played lightly, the same way the
+// larger-board searcher scores lines.
+func (p *ultimatePosition) heuristic(me byte) int {
+	score := 0
+	for _, line := range smallLines {
+		mine, theirs, dead := 0, 0, false
+		for _, board := range line {
+			switch p.macro[board] {
+			case me:
+				mine++
+			case other(me):
+				theirs++
+			case smallDrawn:
+				dead = true
+			}
+		}
+		switch {
+		case dead:
+		case theirs == 0:
+			score += 10 * mine * mine
+		case mine == 0:
+			score -= 10 * theirs * theirs
+		}
+	}
+
+	for board, state := range p.macro {
+		if state != smallOpen {
+			continue
+		}
+		cells := smallCells[board]
+		for _, line := range smallLines {
+			mine, theirs := 0, 0
+			for _, idx := range line {
+				switch p.cells[cells[idx]] {
+				case me:
+					mine++
+				case other(me):
+					theirs++
+				}
+			}
+			switch {
+			case theirs == 0:
+				score += mine * mine
+			case mine == 0:
+				score -= theirs * theirs
+			}
+		}
+	}
+	return max(min(score, decisive/2), -decisive/2)
+}
@@ -0,0 +1,140 @@
+package solver
+
+import (
+	"slices"
+	"testing"
+)
+
+// ultimateCells builds an ultimate board from marks given as small board
+// and cell inside it.
+func ultimateCells(marks map[[2]int]string) []string {
+	out := make([]string, UltimateSize*UltimateSize)
+	for at, symbol := range marks {
+		out[smallCells[at[0]][at[1]]] = symbol
+	}
+	return out
+}
+
+// xTakesTwoBoards has X holding small boards 0 and 1 and two in a row in
+// board 2, needing its cell 2 to win the macro board.
+func xTakesTwoBoards() map[[2]int]string {
+	return map[[2]int]string{
+		{0, 0}: SymbolX, {0, 1}: SymbolX, {0, 2}: SymbolX,
+		{1, 0}: SymbolX, {1, 1}: SymbolX, {1, 2}: SymbolX,
+		{2, 0}: SymbolX, {2, 1}: SymbolX,
+		{3, 0}: SymbolO, {3, 4}: SymbolO,
+		{4, 0}: SymbolO, {4, 4}: SymbolO,
+		{5, 0}: SymbolO, {5, 4}: SymbolO,
+		{6, 4}: SymbolO, {7, 4}: SymbolO,
+	}
+}
+
+func TestAnalyzeUltimate(t *testing.T) {
+	winningCell := smallCells[2][2]
+
+	tests := []struct {
+		name      string
+		next      int
+		toMove    string
+		outcome   Outcome
+		distance  int
+		bestMoves []int
+	}{
+		{"win in one", 2, SymbolX, Win, 1, []int{winningCell}},
+		{"win in one from any board", AnyBoard, SymbolX, Win, 1, []int{winningCell}},
+		{"block the winning cell", 2, SymbolO, Unknown, 0, []int{winningCell}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := NewUltimateBoard(ultimateCells(xTakesTwoBoards()), tt.next)
+			if err != nil {
+				t.Fatalf("NewUltimateBoard: %v", err)
+			}
+			analysis, err := AnalyzeUltimate(board, tt.toMove, 4)
+			if err != nil {
+				t.Fatalf("AnalyzeUltimate: %v", err)
+			}
+			if analysis.Outcome != tt.outcome || analysis.Distance != tt.distance {
+				t.Errorf("got %s in %d, want %s in %d", analysis.Outcome, analysis.Distance, tt.outcome, tt.distance)
+			}
+			if !slices.Equal(analysis.BestMoves, tt.bestMoves) {
+				t.Errorf("best moves %v, want %v", analysis.BestMoves, tt.bestMoves)
+			}
+		})
+	}
+}
+
+func TestAnalyzeUltimateFinishedGame(t *testing.T) {
+	marks := xTakesTwoBoards()
+	marks[[2]int{2, 2}] = SymbolX
+	board, err := NewUltimateBoard(ultimateCells(marks), AnyBoard)
+	if err != nil {
+		t.Fatalf("NewUltimateBoard: %v", err)
+	}
+	if w := board.Winner(); w != SymbolX {
+		t.Fatalf("Winner = %q, want X", w)
+	}
+	analysis, err := AnalyzeUltimate(board, SymbolO, 0)
+	if err != nil {
+		t.Fatalf("AnalyzeUltimate: %v", err)
+	}
+	if !analysis.Exact || analysis.Outcome != Loss || len(analysis.Moves) != 0 {
+		t.Errorf("got %+v, want an exact loss with no moves", analysis)
+	}
+}
+
+func TestUltimatePlaySendsOpponent(t *testing.T) {
+	tests := []struct {
+		name   string
+		marks  map[[2]int]string
+		next   int
+		play   [2]int
+		legal  bool
+		sentTo int
+	}{
+		{"sent to the matching board", nil, AnyBoard, [2]int{4, 7}, true, 7},
+		{"sent to a decided board plays anywhere", xTakesTwoBoards(), 6, [2]int{6, 0}, true, AnyBoard},
+		{"must play in the board sent to", nil, 3, [2]int{4, 0}, false, 0},
+		{"cannot play in a decided board", xTakesTwoBoards(), AnyBoard, [2]int{0, 4}, false, 0},
+		{"cannot play an occupied cell", xTakesTwoBoards(), 2, [2]int{2, 0}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := NewUltimateBoard(ultimateCells(tt.marks), tt.next)
+			if err != nil {
+				t.Fatalf("NewUltimateBoard: %v", err)
+			}
+			pos := smallCells[tt.play[0]][tt.play[1]]
+			if got := board.Legal(pos); got != tt.legal {
+				t.Fatalf("Legal(%d) = %v, want %v", pos, got, tt.legal)
+			}
+			if !tt.legal {
+				return
+			}
+			board.Play(pos, SymbolO)
+			if board.Next != tt.sentTo {
+				t.Errorf("Next = %d, want %d", board.Next, tt.sentTo)
+			}
+		})
+	}
+}
+
+func TestNewUltimateBoardValidates(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []string
+		next  int
+	}{
+		{"wrong size", make([]string, 9), AnyBoard},
+		{"bad symbol", append([]string{"Q"}, make([]string, 80)...), AnyBoard},
+		{"next out of range", make([]string, 81), UltimateSize},
+		{"next already decided", ultimateCells(xTakesTwoBoards()), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewUltimateBoard(tt.cells, tt.next); err == nil {
+				t.Errorf("NewUltimateBoard accepted an invalid board")
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 )
@@ -58,3 +60,10 @@ func ValidateString(s string, minLen, maxLen int) error {
 	}
 	return nil
 }
+
+// NewID returns a random hex identifier with the given prefix, e.g. "corr_3f9a…".
+func NewID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}